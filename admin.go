@@ -0,0 +1,172 @@
+package clog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type adminLogger struct {
+	Name          string     `json:"name"`
+	Mode          string     `json:"mode"`
+	Level         Level      `json:"level"`
+	QueueLength   int        `json:"queue_length"`
+	QueueCapacity int        `json:"queue_capacity"`
+	WriteErrors   uint64     `json:"write_errors"`
+	Dropped       uint64     `json:"dropped"`
+	Muted         bool       `json:"muted"`
+	MutedUntil    *time.Time `json:"muted_until,omitempty"`
+}
+
+func newAdminLogger(info LoggerInfo) *adminLogger {
+	l := &adminLogger{
+		Name:          info.Name,
+		Mode:          info.Mode,
+		Level:         info.Level,
+		QueueLength:   info.QueueLength,
+		QueueCapacity: info.QueueCapacity,
+		WriteErrors:   info.WriteErrors,
+		Dropped:       info.Dropped,
+		Muted:         info.Muted,
+	}
+	if !info.MutedUntil.IsZero() {
+		l.MutedUntil = &info.MutedUntil
+	}
+	return l
+}
+
+// adminUpdate is the request body to update a logger, absent fields are left
+// unchanged.
+type adminUpdate struct {
+	// Name of the new level, e.g. "info".
+	Level *string `json:"level"`
+	// Whether to mute or unmute the logger.
+	Muted *bool `json:"muted"`
+	// Duration to mute the logger, e.g. "10m". Leave empty or zero to mute
+	// indefinitely.
+	MuteFor string `json:"mute_for"`
+}
+
+type adminHandler struct {
+	m *Manager
+}
+
+// AdminHandler returns an HTTP handler to inspect and change loggers of the
+// manager. Requests are served relative to the mount path, use
+// http.StripPrefix if it is not mounted at the root:
+//
+//	GET  /        lists all loggers
+//	GET  /{name}  shows the logger with given name
+//	POST /{name}  updates the logger with given name, with JSON body like
+//	              {"level": "trace", "muted": true, "mute_for": "10m"}
+func (m *Manager) AdminHandler() http.Handler {
+	return &adminHandler{m: m}
+}
+
+// AdminHandler returns an HTTP handler to inspect and change loggers of the
+// default manager.
+func AdminHandler() http.Handler {
+	return mgr.AdminHandler()
+}
+
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(r.URL.Path, "/")
+	if name == "" {
+		if r.Method != http.MethodGet {
+			h.error(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+
+		infos := h.m.Loggers()
+		loggers := make([]*adminLogger, len(infos))
+		for i := range infos {
+			loggers[i] = newAdminLogger(infos[i])
+		}
+		h.json(w, http.StatusOK, loggers)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodPatch:
+		var update adminUpdate
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			h.error(w, http.StatusBadRequest, fmt.Errorf("decode request body: %v", err))
+			return
+		}
+
+		status, err := h.update(name, update)
+		if err != nil {
+			h.error(w, status, err)
+			return
+		}
+	default:
+		h.error(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	info, ok := h.m.LoggerInfo(name)
+	if !ok {
+		h.error(w, http.StatusNotFound, fmt.Errorf("logger with name %q is not available", name))
+		return
+	}
+	h.json(w, http.StatusOK, newAdminLogger(info))
+}
+
+// update applies the update to the logger with given name, and returns the
+// HTTP status code along with the error if any.
+func (h *adminHandler) update(name string, update adminUpdate) (int, error) {
+	if _, ok := h.m.lookup(name); !ok {
+		return http.StatusNotFound, fmt.Errorf("logger with name %q is not available", name)
+	}
+
+	// Validate everything before making any change.
+	var level Level
+	if update.Level != nil {
+		var err error
+		level, err = ParseLevel(*update.Level)
+		if err != nil {
+			return http.StatusBadRequest, err
+		}
+	}
+
+	var muteFor time.Duration
+	if update.MuteFor != "" {
+		var err error
+		muteFor, err = time.ParseDuration(update.MuteFor)
+		if err != nil {
+			return http.StatusBadRequest, fmt.Errorf("parse mute_for: %v", err)
+		} else if muteFor < 0 {
+			return http.StatusBadRequest, fmt.Errorf("negative mute_for %v", muteFor)
+		}
+	}
+
+	var err error
+	if update.Level != nil {
+		err = h.m.SetLevel(name, level)
+	}
+	if err == nil && update.Muted != nil {
+		if *update.Muted {
+			err = h.m.Mute(name, muteFor)
+		} else {
+			err = h.m.Unmute(name)
+		}
+	}
+	if err != nil {
+		// The logger is removed in the meantime.
+		return http.StatusNotFound, err
+	}
+	return http.StatusOK, nil
+}
+
+func (h *adminHandler) json(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (h *adminHandler) error(w http.ResponseWriter, status int, err error) {
+	h.json(w, status, map[string]string{"error": err.Error()})
+}
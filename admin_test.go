@@ -0,0 +1,174 @@
+package clog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_AdminHandler(t *testing.T) {
+	m := NewManager()
+	defer m.Stop()
+
+	assert.Nil(t, m.New("console", ConsoleIniter(), 10, ConsoleConfig{Level: LevelInfo}))
+	assert.Nil(t, m.New("noop", noopIniter("noop")))
+
+	h := m.AdminHandler()
+	do := func(method, path, body string) (int, map[string]interface{}, []map[string]interface{}) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+
+		var obj map[string]interface{}
+		var list []map[string]interface{}
+		if strings.HasPrefix(w.Body.String(), "[") {
+			assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &list))
+		} else {
+			assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &obj))
+		}
+		return w.Code, obj, list
+	}
+
+	t.Run("list", func(t *testing.T) {
+		status, _, list := do(http.MethodGet, "/", "")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t,
+			[]map[string]interface{}{
+				{
+					"name":           "console",
+					"mode":           "console",
+					"level":          "info",
+					"queue_length":   float64(0),
+					"queue_capacity": float64(10),
+					"write_errors":   float64(0),
+					"dropped":        float64(0),
+					"muted":          false,
+				},
+				{
+					"name":           "noop",
+					"mode":           "*clog.noopLogger",
+					"level":          "trace",
+					"queue_length":   float64(0),
+					"queue_capacity": float64(0),
+					"write_errors":   float64(0),
+					"dropped":        float64(0),
+					"muted":          false,
+				},
+			},
+			list,
+		)
+	})
+
+	t.Run("update level", func(t *testing.T) {
+		status, obj, _ := do(http.MethodPost, "/console", `{"level": "warn"}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "warn", obj["level"])
+		assert.Equal(t, LevelWarn, m.loggers()[0].Level())
+	})
+
+	t.Run("write back level", func(t *testing.T) {
+		_, obj, _ := do(http.MethodGet, "/noop", "")
+		body, err := json.Marshal(map[string]interface{}{"level": obj["level"]})
+		assert.Nil(t, err)
+
+		status, obj, _ := do(http.MethodPost, "/noop", string(body))
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "trace", obj["level"])
+		assert.Equal(t, LevelTrace, m.loggers()[1].Level())
+	})
+
+	t.Run("mute temporarily", func(t *testing.T) {
+		status, obj, _ := do(http.MethodPatch, "/console", `{"muted": true, "mute_for": "10m"}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, true, obj["muted"])
+		assert.NotEmpty(t, obj["muted_until"])
+		assert.False(t, m.loggers()[0].accepts(LevelFatal))
+	})
+
+	t.Run("mute indefinitely", func(t *testing.T) {
+		status, obj, _ := do(http.MethodPost, "/noop", `{"muted": true}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, true, obj["muted"])
+		assert.Nil(t, obj["muted_until"])
+
+		status, obj, _ = do(http.MethodPost, "/noop", `{"muted": true, "mute_for": "0s"}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, true, obj["muted"])
+		assert.Nil(t, obj["muted_until"])
+	})
+
+	t.Run("unmute", func(t *testing.T) {
+		status, obj, _ := do(http.MethodPost, "/console", `{"muted": false}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, false, obj["muted"])
+		assert.True(t, m.loggers()[0].accepts(LevelFatal))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			method     string
+			path       string
+			body       string
+			wantStatus int
+			wantError  string
+		}{
+			{
+				name:       "not found",
+				method:     http.MethodGet,
+				path:       "/bob",
+				wantStatus: http.StatusNotFound,
+				wantError:  `logger with name "bob" is not available`,
+			},
+			{
+				name:       "invalid body",
+				method:     http.MethodPost,
+				path:       "/console",
+				body:       `{`,
+				wantStatus: http.StatusBadRequest,
+				wantError:  "decode request body: unexpected EOF",
+			},
+			{
+				name:       "invalid level",
+				method:     http.MethodPost,
+				path:       "/console",
+				body:       `{"level": "verbose"}`,
+				wantStatus: http.StatusBadRequest,
+				wantError:  `unknown level "verbose"`,
+			},
+			{
+				name:       "invalid duration",
+				method:     http.MethodPost,
+				path:       "/console",
+				body:       `{"muted": true, "mute_for": "forever"}`,
+				wantStatus: http.StatusBadRequest,
+				wantError:  "parse mute_for: time: invalid duration",
+			},
+			{
+				name:       "negative duration",
+				method:     http.MethodPost,
+				path:       "/console",
+				body:       `{"muted": true, "mute_for": "-10m"}`,
+				wantStatus: http.StatusBadRequest,
+				wantError:  "negative mute_for -10m0s",
+			},
+			{
+				name:       "method not allowed",
+				method:     http.MethodDelete,
+				path:       "/console",
+				wantStatus: http.StatusMethodNotAllowed,
+				wantError:  "method DELETE not allowed",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				status, obj, _ := do(tt.method, tt.path, tt.body)
+				assert.Equal(t, tt.wantStatus, status)
+				assert.Contains(t, obj["error"], tt.wantError)
+			})
+		}
+	})
+}
@@ -0,0 +1,213 @@
+package clog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// badKey is the key for a value that is missing its key in key-value pairs.
+const badKey = "!BADKEY"
+
+// fieldsFromKeyvals converts alternating key-value pairs into fields, values of
+// the Fields type are merged as is.
+func fieldsFromKeyvals(keyvals []interface{}) Fields {
+	fields := make(Fields, len(keyvals)/2)
+	for i := 0; i < len(keyvals); i++ {
+		if fs, ok := keyvals[i].(Fields); ok {
+			for k, v := range fs {
+				fields[k] = v
+			}
+			continue
+		}
+
+		if i == len(keyvals)-1 {
+			fields[badKey] = keyvals[i]
+			break
+		}
+
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[i])
+		}
+		fields[key] = keyvals[i+1]
+		i++
+	}
+	return fields
+}
+
+// Child is a lightweight handle to write messages to a manager with bound
+// fields and prefix. It is safe for concurrent use.
+type Child struct {
+	m      *Manager
+	prefix string
+	fields Fields
+}
+
+// With returns a child that attaches given fields to every message, fields are
+// given as alternating key-value pairs or values of the Fields type, e.g.
+//
+//	m.With("component", "billing").Info("invoice %d created", id)
+func (m *Manager) With(keyvals ...interface{}) *Child {
+	return (&Child{m: m}).With(keyvals...)
+}
+
+// WithPrefix returns a child that adds "[prefix] " to every message.
+func (m *Manager) WithPrefix(prefix string) *Child {
+	return (&Child{m: m}).WithPrefix(prefix)
+}
+
+// With returns a child of the default manager that attaches given fields to
+// every message, fields are given as alternating key-value pairs or values of
+// the Fields type, e.g.
+//
+//	clog.With("component", "billing").Info("invoice %d created", id)
+func With(keyvals ...interface{}) *Child {
+	return mgr.With(keyvals...)
+}
+
+// WithPrefix returns a child of the default manager that adds "[prefix] " to
+// every message.
+func WithPrefix(prefix string) *Child {
+	return mgr.WithPrefix(prefix)
+}
+
+// With returns a new child that inherits the prefix and fields of c, and
+// attaches given fields in addition.
+func (c *Child) With(keyvals ...interface{}) *Child {
+	fields := make(Fields, len(c.fields)+len(keyvals)/2)
+	for k, v := range c.fields {
+		fields[k] = v
+	}
+	for k, v := range fieldsFromKeyvals(keyvals) {
+		fields[k] = v
+	}
+	return &Child{
+		m:      c.m,
+		prefix: c.prefix,
+		fields: fields,
+	}
+}
+
+// WithPrefix returns a new child that inherits the prefix and fields of c, and
+// adds "[prefix] " after the inherited prefix.
+func (c *Child) WithPrefix(prefix string) *Child {
+	return &Child{
+		m:      c.m,
+		prefix: c.prefix + "[" + strings.Replace(prefix, "%", "%%", -1) + "] ",
+		fields: c.fields,
+	}
+}
+
+// args returns format arguments with bound fields prepended, so fields passed
+// explicitly take precedence.
+func (c *Child) args(v []interface{}) []interface{} {
+	if len(c.fields) == 0 {
+		return v
+	}
+	return append([]interface{}{c.fields}, v...)
+}
+
+// Trace writes formatted log in Trace level.
+func (c *Child) Trace(format string, v ...interface{}) {
+	c.m.write(LevelTrace, 3, c.prefix+format, c.args(v)...)
+}
+
+// Info writes formatted log in Info level.
+func (c *Child) Info(format string, v ...interface{}) {
+	c.m.write(LevelInfo, 3, c.prefix+format, c.args(v)...)
+}
+
+// Warn writes formatted log in Warn level.
+func (c *Child) Warn(format string, v ...interface{}) {
+	c.m.write(LevelWarn, 3, c.prefix+format, c.args(v)...)
+}
+
+// Error writes formatted log in Error level.
+func (c *Child) Error(format string, v ...interface{}) {
+	c.ErrorDepth(4, format, v...)
+}
+
+// ErrorDepth writes formatted log with given skip depth in Error level.
+func (c *Child) ErrorDepth(skip int, format string, v ...interface{}) {
+	c.m.write(LevelError, skip, c.prefix+format, c.args(v)...)
+}
+
+// Fatal writes formatted log in Fatal level then exits.
+func (c *Child) Fatal(format string, v ...interface{}) {
+	c.FatalDepth(4, format, v...)
+}
+
+// FatalDepth writes formatted log with given skip depth in Fatal level then exits.
+func (c *Child) FatalDepth(skip int, format string, v ...interface{}) {
+	c.m.write(LevelFatal, skip, c.prefix+format, c.args(v)...)
+	c.m.exit()
+}
+
+// TraceTo writes formatted log in Trace level to the logger with given name.
+func (c *Child) TraceTo(name, format string, v ...interface{}) {
+	c.m.writeTo(name, LevelTrace, 3, c.prefix+format, c.args(v)...)
+}
+
+// InfoTo writes formatted log in Info level to the logger with given name.
+func (c *Child) InfoTo(name, format string, v ...interface{}) {
+	c.m.writeTo(name, LevelInfo, 3, c.prefix+format, c.args(v)...)
+}
+
+// WarnTo writes formatted log in Warn level to the logger with given name.
+func (c *Child) WarnTo(name, format string, v ...interface{}) {
+	c.m.writeTo(name, LevelWarn, 3, c.prefix+format, c.args(v)...)
+}
+
+// ErrorTo writes formatted log in Error level to the logger with given name.
+func (c *Child) ErrorTo(name, format string, v ...interface{}) {
+	c.ErrorDepthTo(name, 4, format, v...)
+}
+
+// ErrorDepthTo writes formatted log with given skip depth in Error level to
+// the logger with given name.
+func (c *Child) ErrorDepthTo(name string, skip int, format string, v ...interface{}) {
+	c.m.writeTo(name, LevelError, skip, c.prefix+format, c.args(v)...)
+}
+
+// FatalTo writes formatted log in Fatal level to the logger with given name
+// then exits.
+func (c *Child) FatalTo(name, format string, v ...interface{}) {
+	c.FatalDepthTo(name, 4, format, v...)
+}
+
+// FatalDepthTo writes formatted log with given skip depth in Fatal level to
+// the logger with given name then exits.
+func (c *Child) FatalDepthTo(name string, skip int, format string, v ...interface{}) {
+	c.m.writeTo(name, LevelFatal, skip, c.prefix+format, c.args(v)...)
+	c.m.exit()
+}
+
+// TraceContext writes formatted log in Trace level with fields from the
+// context.
+func (c *Child) TraceContext(ctx context.Context, format string, v ...interface{}) {
+	c.m.write(LevelTrace, 3, c.prefix+format, c.args(c.m.contextArgs(ctx, v))...)
+}
+
+// InfoContext writes formatted log in Info level with fields from the context.
+func (c *Child) InfoContext(ctx context.Context, format string, v ...interface{}) {
+	c.m.write(LevelInfo, 3, c.prefix+format, c.args(c.m.contextArgs(ctx, v))...)
+}
+
+// WarnContext writes formatted log in Warn level with fields from the context.
+func (c *Child) WarnContext(ctx context.Context, format string, v ...interface{}) {
+	c.m.write(LevelWarn, 3, c.prefix+format, c.args(c.m.contextArgs(ctx, v))...)
+}
+
+// ErrorContext writes formatted log in Error level with fields from the
+// context.
+func (c *Child) ErrorContext(ctx context.Context, format string, v ...interface{}) {
+	c.m.write(LevelError, 3, c.prefix+format, c.args(c.m.contextArgs(ctx, v))...)
+}
+
+// FatalContext writes formatted log in Fatal level with fields from the
+// context then exits.
+func (c *Child) FatalContext(ctx context.Context, format string, v ...interface{}) {
+	c.m.write(LevelFatal, 3, c.prefix+format, c.args(c.m.contextArgs(ctx, v))...)
+	c.m.exit()
+}
@@ -0,0 +1,79 @@
+package clog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_fieldsFromKeyvals(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyvals []interface{}
+		want    Fields
+	}{
+		{
+			name:    "pairs",
+			keyvals: []interface{}{"component", "billing", "retries", 3},
+			want:    Fields{"component": "billing", "retries": 3},
+		},
+		{
+			name:    "fields",
+			keyvals: []interface{}{Fields{"component": "billing"}, "retries", 3},
+			want:    Fields{"component": "billing", "retries": 3},
+		},
+		{
+			name:    "non-string key",
+			keyvals: []interface{}{1, "one"},
+			want:    Fields{"1": "one"},
+		},
+		{
+			name:    "missing value",
+			keyvals: []interface{}{"component", "billing", "dangling"},
+			want:    Fields{"component": "billing", badKey: "dangling"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, fieldsFromKeyvals(tt.keyvals))
+		})
+	}
+}
+
+func TestChild(t *testing.T) {
+	m := NewManager()
+	defer m.Stop()
+
+	c := make(chan Messager, 1)
+	assert.Nil(t, m.New("message", func(string, ...interface{}) (Logger, error) {
+		return &messageLogger{noopLogger: &noopLogger{name: "message"}, c: c}, nil
+	}))
+
+	billing := m.With("component", "billing").WithPrefix("billing")
+	billing.Info("invoice %d created", 1, Fields{"amount": 100})
+	msg := <-c
+	assert.Equal(t, "[ INFO] [billing] invoice 1 created", msg.String())
+	assert.Equal(t, Fields{"component": "billing", "amount": 100}, msg.Fields())
+
+	invoice := billing.WithPrefix("100%").With("component", "invoice")
+	invoice.WarnTo("message", "sent")
+	msg = <-c
+	assert.Equal(t, "[ WARN] [billing] [100%] sent", msg.String())
+	assert.Equal(t, Fields{"component": "invoice"}, msg.Fields())
+
+	invoice.Error("failed")
+	msg = <-c
+	assert.Contains(t, msg.Caller().File, "child_test.go")
+
+	ctx := NewContext(context.Background(), Fields{"request_id": "abc", "component": "ctx"})
+	billing.InfoContext(ctx, "from context")
+	msg = <-c
+	assert.Equal(t, Fields{"component": "ctx", "request_id": "abc"}, msg.Fields())
+
+	// The parent is not affected by children.
+	m.Info("parent")
+	msg = <-c
+	assert.Equal(t, "[ INFO] parent", msg.String())
+	assert.Nil(t, msg.Fields())
+}
@@ -2,8 +2,12 @@
 package clog
 
 import (
+	"context"
+	"encoding"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 )
 
 // Level is the logging level.
@@ -31,73 +35,197 @@ func (l Level) String() string {
 	case LevelFatal:
 		return "FATAL"
 	default:
-		fmt.Printf("Unexpected Level value: %v\n", int(l))
-		panic("unreachable")
+		return fmt.Sprintf("Level(%d)", int(l))
 	}
 }
 
+// valid returns true if the level is one of the available levels.
+func (l Level) valid() bool {
+	return l >= LevelTrace && l <= LevelFatal
+}
+
+// ParseLevel parses the case-insensitive level name, e.g. "info" or "INFO".
+func ParseLevel(s string) (Level, error) {
+	for l := LevelTrace; l <= LevelFatal; l++ {
+		if strings.EqualFold(s, l.String()) {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown level %q", s)
+}
+
+var (
+	_ encoding.TextMarshaler   = LevelTrace
+	_ encoding.TextUnmarshaler = (*Level)(nil)
+	_ flag.Value               = (*Level)(nil)
+)
+
+// MarshalText implements encoding.TextMarshaler, the level is marshaled as
+// its name in lowercase, e.g. "info". It is also used for JSON encoding.
+func (l Level) MarshalText() ([]byte, error) {
+	if !l.valid() {
+		return nil, fmt.Errorf("invalid level %d", int(l))
+	}
+	return []byte(strings.ToLower(l.String())), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler by ParseLevel. It is also
+// used for JSON decoding.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// Set implements flag.Value by ParseLevel, so the level can be used as a
+// command-line flag, e.g.
+//
+//	level := log.LevelInfo
+//	flag.Var(&level, "log-level", "minimum logging level")
+func (l *Level) Set(s string) error {
+	return l.UnmarshalText([]byte(s))
+}
+
 // Trace writes formatted log in Trace level.
-func Trace(format string, v ...interface{}) {
-	mgr.write(LevelTrace, 0, format, v...)
+func (m *Manager) Trace(format string, v ...interface{}) {
+	m.write(LevelTrace, 3, format, v...)
 }
 
 // Info writes formatted log in Info level.
-func Info(format string, v ...interface{}) {
-	mgr.write(LevelInfo, 0, format, v...)
+func (m *Manager) Info(format string, v ...interface{}) {
+	m.write(LevelInfo, 3, format, v...)
 }
 
 // Warn writes formatted log in Warn level.
-func Warn(format string, v ...interface{}) {
-	mgr.write(LevelWarn, 0, format, v...)
+func (m *Manager) Warn(format string, v ...interface{}) {
+	m.write(LevelWarn, 3, format, v...)
 }
 
 // Error writes formatted log in Error level.
-func Error(format string, v ...interface{}) {
-	ErrorDepth(4, format, v...)
+func (m *Manager) Error(format string, v ...interface{}) {
+	m.ErrorDepth(4, format, v...)
 }
 
 // ErrorDepth writes formatted log with given skip depth in Error level.
-func ErrorDepth(skip int, format string, v ...interface{}) {
-	mgr.write(LevelError, skip, format, v...)
+func (m *Manager) ErrorDepth(skip int, format string, v ...interface{}) {
+	m.write(LevelError, skip, format, v...)
 }
 
 // Fatal writes formatted log in Fatal level then exits.
-func Fatal(format string, v ...interface{}) {
-	FatalDepth(4, format, v...)
+func (m *Manager) Fatal(format string, v ...interface{}) {
+	m.FatalDepth(4, format, v...)
 }
 
 // isTestEnv is true when running tests.
 // In test environment, Fatal or FatalDepth won't stop the manager or exit the program.
 var isTestEnv = false
 
-func exit() {
+func (m *Manager) exit() {
 	if isTestEnv {
 		return
 	}
 
-	Stop()
+	m.Stop()
 	os.Exit(1)
 }
 
+// FatalDepth writes formatted log with given skip depth in Fatal level then exits.
+func (m *Manager) FatalDepth(skip int, format string, v ...interface{}) {
+	m.write(LevelFatal, skip, format, v...)
+	m.exit()
+}
+
+// TraceTo writes formatted log in Trace level to the logger with given name.
+func (m *Manager) TraceTo(name, format string, v ...interface{}) {
+	m.writeTo(name, LevelTrace, 3, format, v...)
+}
+
+// InfoTo writes formatted log in Info level to the logger with given name.
+func (m *Manager) InfoTo(name, format string, v ...interface{}) {
+	m.writeTo(name, LevelInfo, 3, format, v...)
+}
+
+// WarnTo writes formatted log in Warn level to the logger with given name.
+func (m *Manager) WarnTo(name, format string, v ...interface{}) {
+	m.writeTo(name, LevelWarn, 3, format, v...)
+}
+
+// ErrorTo writes formatted log in Error level to the logger with given name.
+func (m *Manager) ErrorTo(name, format string, v ...interface{}) {
+	m.ErrorDepthTo(name, 4, format, v...)
+}
+
+// ErrorDepthTo writes formatted log with given skip depth in Error level to
+// the logger with given name.
+func (m *Manager) ErrorDepthTo(name string, skip int, format string, v ...interface{}) {
+	m.writeTo(name, LevelError, skip, format, v...)
+}
+
+// FatalTo writes formatted log in Fatal level to the logger with given name
+// then exits.
+func (m *Manager) FatalTo(name, format string, v ...interface{}) {
+	m.FatalDepthTo(name, 4, format, v...)
+}
+
+// FatalDepthTo writes formatted log with given skip depth in Fatal level to
+// the logger with given name then exits.
+func (m *Manager) FatalDepthTo(name string, skip int, format string, v ...interface{}) {
+	m.writeTo(name, LevelFatal, skip, format, v...)
+	m.exit()
+}
+
+// Trace writes formatted log in Trace level.
+func Trace(format string, v ...interface{}) {
+	mgr.write(LevelTrace, 3, format, v...)
+}
+
+// Info writes formatted log in Info level.
+func Info(format string, v ...interface{}) {
+	mgr.write(LevelInfo, 3, format, v...)
+}
+
+// Warn writes formatted log in Warn level.
+func Warn(format string, v ...interface{}) {
+	mgr.write(LevelWarn, 3, format, v...)
+}
+
+// Error writes formatted log in Error level.
+func Error(format string, v ...interface{}) {
+	ErrorDepth(4, format, v...)
+}
+
+// ErrorDepth writes formatted log with given skip depth in Error level.
+func ErrorDepth(skip int, format string, v ...interface{}) {
+	mgr.write(LevelError, skip, format, v...)
+}
+
+// Fatal writes formatted log in Fatal level then exits.
+func Fatal(format string, v ...interface{}) {
+	FatalDepth(4, format, v...)
+}
+
 // FatalDepth writes formatted log with given skip depth in Fatal level then exits.
 func FatalDepth(skip int, format string, v ...interface{}) {
 	mgr.write(LevelFatal, skip, format, v...)
-	exit()
+	mgr.exit()
 }
 
 // TraceTo writes formatted log in Trace level to the logger with given name.
 func TraceTo(name, format string, v ...interface{}) {
-	mgr.writeTo(name, LevelTrace, 0, format, v...)
+	mgr.writeTo(name, LevelTrace, 3, format, v...)
 }
 
 // InfoTo writes formatted log in Info level to the logger with given name.
 func InfoTo(name, format string, v ...interface{}) {
-	mgr.writeTo(name, LevelInfo, 0, format, v...)
+	mgr.writeTo(name, LevelInfo, 3, format, v...)
 }
 
 // WarnTo writes formatted log in Warn level to the logger with given name.
 func WarnTo(name, format string, v ...interface{}) {
-	mgr.writeTo(name, LevelWarn, 0, format, v...)
+	mgr.writeTo(name, LevelWarn, 3, format, v...)
 }
 
 // ErrorTo writes formatted log in Error level to the logger with given name.
@@ -121,11 +249,30 @@ func FatalTo(name, format string, v ...interface{}) {
 // the logger with given name then exits.
 func FatalDepthTo(name string, skip int, format string, v ...interface{}) {
 	mgr.writeTo(name, LevelFatal, skip, format, v...)
-	exit()
+	mgr.exit()
 }
 
 // Stop propagates cancellation to all loggers and waits for completion.
 // This function should always be called before exiting the program.
 func Stop() {
-	mgr.stop()
+	mgr.Stop()
+}
+
+// Reset stops the default manager if it is running, then removes all loggers
+// and returns it to the running state.
+func Reset() {
+	mgr.Reset()
+}
+
+// StopContext propagates cancellation to all loggers and waits for completion
+// until ctx is done, it returns a *DrainError with names of loggers that have
+// not been drained in time.
+func StopContext(ctx context.Context) error {
+	return mgr.StopContext(ctx)
+}
+
+// Flush waits until all messages queued by the time of the call are written
+// by loggers of the default manager, or ctx is done.
+func Flush(ctx context.Context) error {
+	return mgr.Flush(ctx)
 }
@@ -1,8 +1,13 @@
 package clog
 
 import (
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -12,12 +17,70 @@ func init() {
 }
 
 func TestLevel_String(t *testing.T) {
-	invalidLevel := Level(-1)
-	defer func() {
-		assert.NotNil(t, recover())
-	}()
+	assert.Equal(t, "TRACE", LevelTrace.String())
+	assert.Equal(t, "FATAL", LevelFatal.String())
+	assert.Equal(t, "Level(-1)", Level(-1).String())
+	assert.Equal(t, "Level(7)", Level(7).String())
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Level
+		wantErr error
+	}{
+		{s: "trace", want: LevelTrace},
+		{s: "INFO", want: LevelInfo},
+		{s: "Warn", want: LevelWarn},
+		{s: "error", want: LevelError},
+		{s: "fatal", want: LevelFatal},
+		{s: "verbose", wantErr: errors.New(`unknown level "verbose"`)},
+		{s: "", wantErr: errors.New(`unknown level ""`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseLevel(tt.s)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLevel_text(t *testing.T) {
+	p, err := LevelWarn.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "warn", string(p))
+
+	_, err = Level(7).MarshalText()
+	assert.Equal(t, errors.New("invalid level 7"), err)
+
+	var level Level
+	assert.Nil(t, level.UnmarshalText([]byte("ERROR")))
+	assert.Equal(t, LevelError, level)
+	assert.Equal(t, errors.New(`unknown level "verbose"`), level.UnmarshalText([]byte("verbose")))
+	assert.Equal(t, LevelError, level)
 
-	_ = invalidLevel.String()
+	var v struct {
+		Level Level `json:"level"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(`{"level": "info"}`), &v))
+	assert.Equal(t, LevelInfo, v.Level)
+	p, err = json.Marshal(v)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"level":"info"}`, string(p))
+}
+
+func TestLevel_flag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	level := LevelInfo
+	fs.Var(&level, "log-level", "minimum logging level")
+
+	assert.Nil(t, fs.Parse([]string{"-log-level=warn"}))
+	assert.Equal(t, LevelWarn, level)
+
+	err := fs.Parse([]string{"-log-level=verbose"})
+	assert.Equal(t, `invalid value "verbose" for flag -log-level: unknown level "verbose"`, err.Error())
 }
 
 type chanConfig struct {
@@ -201,3 +264,97 @@ func Test_writeToNamedLogger(t *testing.T) {
 		})
 	}
 }
+
+func TestManager(t *testing.T) {
+	t.Parallel()
+
+	m1 := NewManager()
+	m2 := NewManager()
+	defer m1.Stop()
+	defer m2.Stop()
+
+	c1 := make(chan string, 1)
+	c2 := make(chan string, 1)
+	assert.Nil(t, m1.New("alice", chanLoggerIniter("alice", LevelTrace), chanConfig{c: c1}))
+	assert.Nil(t, m2.New("alice", chanLoggerIniter("alice", LevelTrace), chanConfig{c: c2}))
+	assert.Equal(t, 1, m1.len())
+	assert.Equal(t, 1, m2.len())
+
+	m1.Info("from manager %d", 1)
+	assert.Equal(t, "[ INFO] from manager 1", <-c1)
+
+	m2.Error("from manager %d", 2)
+	assert.Contains(t, <-c2, "clog_test.go")
+
+	m2.WarnTo("alice", "to alice")
+	assert.Equal(t, "[ WARN] to alice", <-c2)
+
+	select {
+	case s := <-c1:
+		t.Fatalf("unexpected message in manager 1: %s", s)
+	default:
+	}
+
+	m1.Remove("alice")
+	assert.Equal(t, 0, m1.len())
+	assert.Equal(t, 1, m2.len())
+}
+
+func TestManager_caller(t *testing.T) {
+	m := NewManager()
+	defer m.Stop()
+
+	c1 := make(chan string, 1)
+	c2 := make(chan string, 1)
+	assert.Nil(t, m.New("alice", chanLoggerIniter("alice", LevelTrace), chanConfig{c: c1}, CallerLevel(LevelInfo)))
+	assert.Nil(t, m.New("bob", chanLoggerIniter("bob", LevelTrace), chanConfig{c: c2}))
+
+	m.Trace("trace")
+	assert.Equal(t, "[TRACE] trace", <-c1)
+	assert.Equal(t, "[TRACE] trace", <-c2)
+
+	m.Info("info")
+	assert.Contains(t, <-c1, "clog_test.go")
+	assert.Equal(t, "[ INFO] info", <-c2)
+
+	m.SetCallerLevel(LevelTrace)
+	m.Trace("trace")
+	assert.Equal(t, "[TRACE] trace", <-c1)
+	assert.Contains(t, <-c2, "clog_test.go")
+
+	m.WarnTo("bob", "warn")
+	assert.Contains(t, <-c2, "clog_test.go")
+}
+
+var _ Logger = (*messageLogger)(nil)
+
+// messageLogger sends every message as is to the channel.
+type messageLogger struct {
+	*noopLogger
+	c chan Messager
+}
+
+func (l *messageLogger) Write(m Messager) error {
+	l.c <- m
+	return nil
+}
+
+func TestManager_SetTimeLocation(t *testing.T) {
+	m := NewManager()
+	defer m.Stop()
+
+	c := make(chan Messager, 1)
+	assert.Nil(t, m.New("message", func(string, ...interface{}) (Logger, error) {
+		return &messageLogger{noopLogger: &noopLogger{name: "message"}, c: c}, nil
+	}))
+
+	loc := time.FixedZone("UTC+8", 8*60*60)
+	m.SetTimeLocation(loc)
+
+	before := time.Now()
+	m.Info("hello")
+	msg := <-c
+	assert.Equal(t, loc, msg.Time().Location())
+	assert.False(t, msg.Time().Before(before))
+	assert.False(t, msg.Time().After(time.Now()))
+}
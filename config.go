@@ -0,0 +1,575 @@
+package clog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ConfigDecoder decodes mode-specific options of a logger from JSON into the
+// config object to be passed to the initer of the mode. The options may be
+// empty if not given. A nil decoder means the mode takes no options.
+type ConfigDecoder func(level Level, options json.RawMessage) (interface{}, error)
+
+type modeEntry struct {
+	initer Initer
+	decode ConfigDecoder
+}
+
+var modes = struct {
+	sync.RWMutex
+	entries map[string]modeEntry
+}{
+	entries: make(map[string]modeEntry),
+}
+
+// RegisterMode registers the initer and the config decoder of a mode with
+// given name to be used by LoadConfig. Registering a mode with the same name
+// overwrites the previous one.
+func RegisterMode(mode string, initer Initer, decode ConfigDecoder) {
+	modes.Lock()
+	defer modes.Unlock()
+	modes.entries[mode] = modeEntry{
+		initer: initer,
+		decode: decode,
+	}
+}
+
+func lookupMode(mode string) (modeEntry, bool) {
+	modes.RLock()
+	defer modes.RUnlock()
+	e, ok := modes.entries[mode]
+	return e, ok
+}
+
+// Config is the declarative configuration of loggers.
+type Config struct {
+	Loggers []LoggerConfig `json:"loggers"`
+}
+
+// LoggerConfig is the declarative configuration of a logger.
+type LoggerConfig struct {
+	// Name of the logger, default is the mode name.
+	Name string `json:"name"`
+	// Mode name that has been registered by RegisterMode.
+	Mode string `json:"mode"`
+	// Minimum logging level, e.g. "info". Default is "trace".
+	Level string `json:"level"`
+	// Buffer size of the logger.
+	BufferSize int `json:"buffer_size"`
+	// Overflow policy, one of "block", "drop_newest", "drop_oldest" and
+	// "block_timeout". Default is "block".
+	Overflow string `json:"overflow"`
+	// Timeout of the "block_timeout" overflow policy, e.g. "100ms". It is
+	// required by the policy.
+	OverflowTimeout string `json:"overflow_timeout"`
+	// Minimum level of messages to carry caller information, default is the
+	// level set by SetCallerLevel.
+	CallerLevel string `json:"caller_level"`
+	// Mode-specific options.
+	Options json.RawMessage `json:"options"`
+}
+
+// decodeStrict decodes JSON data into v and rejects unknown fields. It is a
+// noop if data is empty.
+func decodeStrict(data []byte, v interface{}) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+var overflowPolicies = map[string]OverflowPolicy{
+	"":              OverflowBlock,
+	"block":         OverflowBlock,
+	"drop_newest":   OverflowDropNewest,
+	"drop_oldest":   OverflowDropOldest,
+	"block_timeout": OverflowBlockTimeout,
+}
+
+// build validates the config and returns the name, the initer and the options
+// to create the logger by New.
+func (c *LoggerConfig) build() (name string, initer Initer, opts []interface{}, err error) {
+	name = c.Name
+	if name == "" {
+		name = c.Mode
+	}
+	if name == "" {
+		return "", nil, nil, errors.New("either name or mode is required")
+	}
+
+	wrap := func(err error) error {
+		return fmt.Errorf("logger %q: %v", name, err)
+	}
+
+	if c.Mode == "" {
+		return "", nil, nil, wrap(errors.New("empty mode"))
+	}
+	mode, ok := lookupMode(c.Mode)
+	if !ok {
+		return "", nil, nil, wrap(fmt.Errorf("unknown mode %q", c.Mode))
+	}
+
+	var level Level
+	if c.Level != "" {
+		level, err = ParseLevel(c.Level)
+		if err != nil {
+			return "", nil, nil, wrap(fmt.Errorf("level: %v", err))
+		}
+	}
+
+	if c.BufferSize < 0 {
+		return "", nil, nil, wrap(fmt.Errorf("negative buffer size %d", c.BufferSize))
+	}
+	opts = append(opts, c.BufferSize)
+
+	policy, ok := overflowPolicies[c.Overflow]
+	if !ok {
+		return "", nil, nil, wrap(fmt.Errorf("unknown overflow policy %q", c.Overflow))
+	}
+	overflow := OverflowConfig{Policy: policy}
+	if c.OverflowTimeout != "" {
+		overflow.Timeout, err = time.ParseDuration(c.OverflowTimeout)
+		if err != nil {
+			return "", nil, nil, wrap(fmt.Errorf("overflow timeout: %v", err))
+		} else if overflow.Timeout < 0 {
+			return "", nil, nil, wrap(fmt.Errorf("negative overflow timeout %v", overflow.Timeout))
+		}
+	}
+	if policy == OverflowBlockTimeout && overflow.Timeout == 0 {
+		return "", nil, nil, wrap(errors.New(`overflow timeout is required by "block_timeout"`))
+	}
+	opts = append(opts, overflow)
+
+	if c.CallerLevel != "" {
+		callerLevel, err := ParseLevel(c.CallerLevel)
+		if err != nil {
+			return "", nil, nil, wrap(fmt.Errorf("caller level: %v", err))
+		}
+		opts = append(opts, CallerLevel(callerLevel))
+	}
+
+	if mode.decode == nil {
+		options := bytes.TrimSpace(c.Options)
+		if len(options) > 0 && !bytes.Equal(options, []byte("null")) {
+			return "", nil, nil, wrap(fmt.Errorf("mode %q takes no options", c.Mode))
+		}
+		return name, mode.initer, opts, nil
+	}
+
+	cfg, err := mode.decode(level, c.Options)
+	if err != nil {
+		return "", nil, nil, wrap(fmt.Errorf("options: %v", err))
+	}
+	opts = append(opts, cfg)
+	return name, mode.initer, opts, nil
+}
+
+// ParseConfig parses and validates the JSON configuration from r.
+func ParseConfig(r io.Reader) (*Config, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var cfg Config
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("decode: %v", err)
+	}
+
+	names := make(map[string]bool, len(cfg.Loggers))
+	for i := range cfg.Loggers {
+		name, _, _, err := cfg.Loggers[i].build()
+		if err != nil {
+			return nil, err
+		}
+
+		if names[name] {
+			return nil, fmt.Errorf("logger %q: duplicated name", name)
+		}
+		names[name] = true
+	}
+	return &cfg, nil
+}
+
+// appliedConfig is the config of a logger that has been applied.
+type appliedConfig struct {
+	mode string
+	opts []interface{}
+}
+
+// ApplyConfig applies the configuration to the managed list. Loggers that are
+// new or whose config has changed since the last applied configuration are
+// (re)created by New, so no message is lost or reordered during the swap.
+// Loggers that are unchanged keep running as is, and loggers created by a
+// previous configuration but absent from this one are removed. Loggers
+// created by New directly are never removed.
+//
+// If a logger fails to initialize, the error is returned and the rest of the
+// configuration is not applied, loggers that have not been processed keep
+// running with their previous config.
+func (m *Manager) ApplyConfig(cfg *Config) error {
+	m.configMu.Lock()
+	defer m.configMu.Unlock()
+
+	if m.stopped() {
+		return ErrStopped
+	}
+
+	type entry struct {
+		name   string
+		initer Initer
+		config appliedConfig
+	}
+	entries := make([]entry, 0, len(cfg.Loggers))
+	names := make(map[string]bool, len(cfg.Loggers))
+	for i := range cfg.Loggers {
+		name, initer, opts, err := cfg.Loggers[i].build()
+		if err != nil {
+			return err
+		}
+
+		if names[name] {
+			return fmt.Errorf("logger %q: duplicated name", name)
+		}
+		names[name] = true
+
+		entries = append(entries, entry{
+			name:   name,
+			initer: initer,
+			config: appliedConfig{
+				mode: cfg.Loggers[i].Mode,
+				opts: opts,
+			},
+		})
+	}
+
+	if m.configs == nil {
+		m.configs = make(map[string]appliedConfig)
+	}
+	for _, e := range entries {
+		prev, ok := m.configs[e.name]
+		if ok && reflect.DeepEqual(prev, e.config) {
+			if _, ok = m.lookup(e.name); ok {
+				continue
+			}
+		}
+
+		if err := m.New(e.name, e.initer, e.config.opts...); err != nil {
+			return fmt.Errorf("logger %q: %v", e.name, err)
+		}
+		m.configs[e.name] = e.config
+	}
+
+	for name := range m.configs {
+		if names[name] {
+			continue
+		}
+
+		m.Remove(name)
+		delete(m.configs, name)
+	}
+	return nil
+}
+
+// ApplyConfig applies the configuration to the managed list of the default
+// manager.
+func ApplyConfig(cfg *Config) error {
+	return mgr.ApplyConfig(cfg)
+}
+
+// LoadConfig parses the JSON configuration from r and applies it by
+// ApplyConfig, therefore it can be called again to reload the configuration.
+// For example:
+//
+//	{
+//	  "loggers": [
+//	    {"mode": "console", "level": "info", "buffer_size": 100},
+//	    {"mode": "file", "level": "warn", "options": {"filename": "clog.log", "format": "json"}}
+//	  ]
+//	}
+func (m *Manager) LoadConfig(r io.Reader) error {
+	cfg, err := ParseConfig(r)
+	if err != nil {
+		return err
+	}
+	return m.ApplyConfig(cfg)
+}
+
+// LoadConfig parses the JSON configuration from r and applies it to the
+// managed list of the default manager.
+func LoadConfig(r io.Reader) error {
+	return mgr.LoadConfig(r)
+}
+
+// DefaultConfigWatchInterval is the default interval to check changes of the
+// configuration file.
+const DefaultConfigWatchInterval = 5 * time.Second
+
+// loadConfigFile loads the configuration file and returns its file info
+// before reading.
+func (m *Manager) loadConfigFile(filename string) (os.FileInfo, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat: %v", err)
+	}
+	return fi, m.LoadConfig(f)
+}
+
+// WatchConfig loads the configuration file, then checks the file for changes
+// with given interval and reloads it until ctx is done or the manager is
+// stopped. Default interval is DefaultConfigWatchInterval. An error is
+// returned only if the first load fails, errors of reloading are passed to the
+// error handler and the current configuration keeps running.
+func (m *Manager) WatchConfig(ctx context.Context, filename string, interval time.Duration) error {
+	fi, err := m.loadConfigFile(filename)
+	if err != nil {
+		return fmt.Errorf("load config: %v", err)
+	}
+
+	if interval <= 0 {
+		interval = DefaultConfigWatchInterval
+	}
+
+	m.mu.Lock()
+	stopped := m.ctx.Done()
+	m.mu.Unlock()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		modTime, size := fi.ModTime(), fi.Size()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stopped:
+				return
+			case <-ticker.C:
+			}
+
+			fi, err := os.Stat(filename)
+			if err != nil {
+				m.handleError("", nil, fmt.Errorf("watch config: %v", err))
+				continue
+			} else if fi.ModTime().Equal(modTime) && fi.Size() == size {
+				continue
+			}
+
+			fi, err = m.loadConfigFile(filename)
+			if fi != nil {
+				modTime, size = fi.ModTime(), fi.Size()
+			}
+			if err != nil {
+				m.handleError("", nil, fmt.Errorf("reload config: %v", err))
+			}
+		}
+	}()
+	return nil
+}
+
+// WatchConfig loads the configuration file and reloads it on changes for the
+// default manager.
+func WatchConfig(ctx context.Context, filename string, interval time.Duration) error {
+	return mgr.WatchConfig(ctx, filename, interval)
+}
+
+// formatterByName returns the builtin formatter with given name, it returns
+// nil for an empty name.
+func formatterByName(name string) (Formatter, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "text":
+		return TextFormatter{}, nil
+	case "json":
+		return JSONFormatter{}, nil
+	case "logfmt":
+		return LogfmtFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", name)
+	}
+}
+
+func decodeConsoleConfig(level Level, options json.RawMessage) (interface{}, error) {
+	var opts struct {
+		Format string `json:"format"`
+	}
+	if err := decodeStrict(options, &opts); err != nil {
+		return nil, err
+	}
+
+	formatter, err := formatterByName(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	return ConsoleConfig{
+		Level:     level,
+		Formatter: formatter,
+	}, nil
+}
+
+func decodeFileConfig(level Level, options json.RawMessage) (interface{}, error) {
+	var opts struct {
+		Filename     string `json:"filename"`
+		Format       string `json:"format"`
+		Rotate       bool   `json:"rotate"`
+		Daily        bool   `json:"daily"`
+		MaxSize      int64  `json:"max_size"`
+		MaxLines     int64  `json:"max_lines"`
+		MaxDays      int64  `json:"max_days"`
+		MaxBackups   int    `json:"max_backups"`
+		MaxTotalSize int64  `json:"max_total_size"`
+		Compress     bool   `json:"compress"`
+
+		Interval        string `json:"interval"`
+		Location        string `json:"location"`
+		FilenamePattern string `json:"filename_pattern"`
+		DateFormat      string `json:"date_format"`
+		TimestampFormat string `json:"timestamp_format"`
+	}
+	if err := decodeStrict(options, &opts); err != nil {
+		return nil, err
+	}
+
+	formatter, err := formatterByName(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	filename := opts.Filename
+	if filename == "" {
+		filename = "clog.log"
+	}
+
+	var interval time.Duration
+	if opts.Interval != "" {
+		interval, err = time.ParseDuration(opts.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("parse interval: %v", err)
+		}
+	}
+
+	var location *time.Location
+	if opts.Location != "" {
+		location, err = time.LoadLocation(opts.Location)
+		if err != nil {
+			return nil, fmt.Errorf("load location: %v", err)
+		}
+	}
+	return FileConfig{
+		Level:     level,
+		Filename:  filename,
+		Formatter: formatter,
+		FileRotationConfig: FileRotationConfig{
+			Rotate:       opts.Rotate,
+			Daily:        opts.Daily,
+			MaxSize:      opts.MaxSize,
+			MaxLines:     opts.MaxLines,
+			MaxDays:      opts.MaxDays,
+			MaxBackups:   opts.MaxBackups,
+			MaxTotalSize: opts.MaxTotalSize,
+			Compress:     opts.Compress,
+			Interval:     interval,
+			Location:     location,
+
+			FilenamePattern: opts.FilenamePattern,
+			DateFormat:      opts.DateFormat,
+			TimestampFormat: opts.TimestampFormat,
+		},
+	}, nil
+}
+
+// webhookClientByTimeout returns an HTTP client with given timeout for webhook
+// loggers, it returns nil for an empty timeout to use the default client.
+func webhookClientByTimeout(timeout string) (*http.Client, error) {
+	if timeout == "" {
+		return nil, nil
+	}
+
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return nil, fmt.Errorf("parse timeout: %v", err)
+	} else if d < 0 {
+		return nil, fmt.Errorf("negative timeout %v", d)
+	}
+	return &http.Client{Timeout: d}, nil
+}
+
+func decodeSlackConfig(level Level, options json.RawMessage) (interface{}, error) {
+	var opts struct {
+		URL     string   `json:"url"`
+		Colors  []string `json:"colors"`
+		Timeout string   `json:"timeout"`
+	}
+	if err := decodeStrict(options, &opts); err != nil {
+		return nil, err
+	}
+
+	if opts.URL == "" {
+		return nil, errors.New("empty URL")
+	}
+
+	client, err := webhookClientByTimeout(opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return SlackConfig{
+		Level:  level,
+		URL:    opts.URL,
+		Colors: opts.Colors,
+		Client: client,
+	}, nil
+}
+
+func decodeDiscordConfig(level Level, options json.RawMessage) (interface{}, error) {
+	var opts struct {
+		URL      string   `json:"url"`
+		Username string   `json:"username"`
+		Titles   []string `json:"titles"`
+		Colors   []int    `json:"colors"`
+		Timeout  string   `json:"timeout"`
+	}
+	if err := decodeStrict(options, &opts); err != nil {
+		return nil, err
+	}
+
+	if opts.URL == "" {
+		return nil, errors.New("empty URL")
+	}
+
+	client, err := webhookClientByTimeout(opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return DiscordConfig{
+		Level:    level,
+		URL:      opts.URL,
+		Username: opts.Username,
+		Titles:   opts.Titles,
+		Colors:   opts.Colors,
+		Client:   client,
+	}, nil
+}
+
+func init() {
+	RegisterMode(ModeConsole, ConsoleIniter(), decodeConsoleConfig)
+	RegisterMode(ModeFile, FileIniter(), decodeFileConfig)
+	RegisterMode(ModeSlack, SlackIniter(), decodeSlackConfig)
+	RegisterMode(ModeDiscord, DiscordIniter(), decodeDiscordConfig)
+}
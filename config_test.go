@@ -0,0 +1,302 @@
+package clog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_LoadConfig(t *testing.T) {
+	_ = os.MkdirAll("test", os.ModePerm)
+	defer os.RemoveAll("test")
+
+	c := make(chan string, 1)
+	RegisterMode("TestManager_LoadConfig", chanLoggerIniter("custom", LevelWarn),
+		func(_ Level, options json.RawMessage) (interface{}, error) {
+			return chanConfig{c: c}, nil
+		},
+	)
+
+	m := NewManager()
+	defer m.Stop()
+
+	err := m.LoadConfig(strings.NewReader(`{
+  "loggers": [
+    {"mode": "console", "level": "info", "buffer_size": 100, "overflow": "drop_oldest", "options": {"format": "logfmt"}},
+    {"name": "audit", "mode": "file", "level": "warn", "caller_level": "info",
+     "options": {"filename": "` + filepath.Join("test", "audit.log") + `", "format": "json", "rotate": true, "max_size": 1024, "interval": "1h", "location": "UTC"}},
+    {"name": "custom", "mode": "TestManager_LoadConfig"}
+  ]
+}`))
+	assert.Nil(t, err)
+
+	loggers := m.loggers()
+	assert.Len(t, loggers, 3)
+
+	assert.Equal(t, "console", loggers[0].Name())
+	assert.Equal(t, LevelInfo, loggers[0].Level())
+	assert.Equal(t, 100, cap(loggers[0].msgChan))
+	assert.Equal(t, OverflowDropOldest, loggers[0].overflow.Policy)
+	assert.Equal(t, LogfmtFormatter{}, loggers[0].Logger.(*consoleLogger).formatter)
+
+	assert.Equal(t, "audit", loggers[1].Name())
+	assert.Equal(t, LevelWarn, loggers[1].Level())
+	assert.True(t, loggers[1].hasCallerLevel)
+	assert.Equal(t, LevelInfo, loggers[1].callerLevel)
+	fl := loggers[1].Logger.(*fileLogger)
+	assert.Equal(t, filepath.Join("test", "audit.log"), fl.filename)
+	assert.Equal(t, JSONFormatter{}, fl.formatter)
+	assert.Equal(t, FileRotationConfig{Rotate: true, MaxSize: 1024, Interval: time.Hour, Location: time.UTC}, fl.rotationConfig)
+
+	m.Warn("to custom")
+	assert.Equal(t, "[ WARN] to custom", <-c)
+}
+
+func TestManager_ApplyConfig(t *testing.T) {
+	m := NewManager()
+	defer m.Stop()
+
+	assert.Nil(t, m.New("manual", noopIniter("manual")))
+
+	parse := func(config string) *Config {
+		cfg, err := ParseConfig(strings.NewReader(config))
+		assert.Nil(t, err)
+		return cfg
+	}
+
+	assert.Nil(t, m.ApplyConfig(parse(`{
+  "loggers": [
+    {"name": "unchanged", "mode": "console", "level": "info"},
+    {"name": "changed", "mode": "console", "level": "info"},
+    {"name": "removed", "mode": "console"}
+  ]
+}`)))
+	assert.Equal(t, 4, m.len())
+
+	unchanged, _ := m.lookup("unchanged")
+	changed, _ := m.lookup("changed")
+
+	// Runtime changes of unchanged loggers are kept.
+	assert.Nil(t, m.SetLevel("unchanged", LevelError))
+
+	assert.Nil(t, m.ApplyConfig(parse(`{
+  "loggers": [
+    {"name": "unchanged", "mode": "console", "level": "INFO"},
+    {"name": "changed", "mode": "console", "level": "warn"},
+    {"name": "added", "mode": "console"}
+  ]
+}`)))
+
+	l, ok := m.lookup("unchanged")
+	assert.True(t, ok)
+	assert.True(t, unchanged == l)
+	assert.Equal(t, LevelError, l.Level())
+
+	l, ok = m.lookup("changed")
+	assert.True(t, ok)
+	assert.False(t, changed == l)
+	assert.Equal(t, LevelWarn, l.Level())
+
+	_, ok = m.lookup("removed")
+	assert.False(t, ok)
+	_, ok = m.lookup("added")
+	assert.True(t, ok)
+	_, ok = m.lookup("manual")
+	assert.True(t, ok)
+	assert.Equal(t, 4, m.len())
+
+	// A logger removed by hand is recreated even if its config is unchanged.
+	m.Remove("added")
+	assert.Nil(t, m.ApplyConfig(parse(`{"loggers": [{"name": "added", "mode": "console"}]}`)))
+	_, ok = m.lookup("added")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"manual", "added"}, loggerNames(m))
+}
+
+// loggerNames returns names of loggers managed by m in order.
+func loggerNames(m *Manager) []string {
+	var names []string
+	for _, l := range m.loggers() {
+		names = append(names, l.Name())
+	}
+	return names
+}
+
+func TestManager_WatchConfig(t *testing.T) {
+	_ = os.MkdirAll("test", os.ModePerm)
+	defer os.RemoveAll("test")
+
+	filename := filepath.Join("test", "clog.json")
+	writeConfig := func(level string) {
+		config := `{"loggers": [{"mode": "console", "level": "` + level + `"}]}`
+		assert.Nil(t, ioutil.WriteFile(filename, []byte(config), 0644))
+	}
+
+	m := NewManager()
+	defer m.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := m.WatchConfig(ctx, filepath.Join("test", "404.json"), 0)
+	assert.NotNil(t, err)
+
+	writeConfig("info")
+	assert.Nil(t, m.WatchConfig(ctx, filename, 10*time.Millisecond))
+	l, ok := m.lookup("console")
+	assert.True(t, ok)
+	assert.Equal(t, LevelInfo, l.Level())
+
+	writeConfig("error")
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if l, ok = m.lookup("console"); ok && l.Level() == LevelError {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, LevelError, l.Level())
+}
+
+func TestParseConfig(t *testing.T) {
+	RegisterMode("TestParseConfig", chanLoggerIniter("custom", LevelTrace), nil)
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr error
+	}{
+		{
+			name:    "invalid JSON",
+			config:  `{`,
+			wantErr: errors.New("decode: unexpected EOF"),
+		},
+		{
+			name:    "unknown field",
+			config:  `{"loggers": [{"mode": "console", "levle": "info"}]}`,
+			wantErr: errors.New(`decode: json: unknown field "levle"`),
+		},
+		{
+			name:    "no name or mode",
+			config:  `{"loggers": [{}]}`,
+			wantErr: errors.New("either name or mode is required"),
+		},
+		{
+			name:    "empty mode",
+			config:  `{"loggers": [{"name": "alice"}]}`,
+			wantErr: errors.New(`logger "alice": empty mode`),
+		},
+		{
+			name:    "unknown mode",
+			config:  `{"loggers": [{"name": "alice", "mode": "carrier-pigeon"}]}`,
+			wantErr: errors.New(`logger "alice": unknown mode "carrier-pigeon"`),
+		},
+		{
+			name:    "invalid level",
+			config:  `{"loggers": [{"mode": "console", "level": "verbose"}]}`,
+			wantErr: errors.New(`logger "console": level: unknown level "verbose"`),
+		},
+		{
+			name:    "negative buffer size",
+			config:  `{"loggers": [{"mode": "console", "buffer_size": -1}]}`,
+			wantErr: errors.New(`logger "console": negative buffer size -1`),
+		},
+		{
+			name:    "unknown overflow policy",
+			config:  `{"loggers": [{"mode": "console", "overflow": "explode"}]}`,
+			wantErr: errors.New(`logger "console": unknown overflow policy "explode"`),
+		},
+		{
+			name:    "negative overflow timeout",
+			config:  `{"loggers": [{"mode": "console", "overflow": "block_timeout", "overflow_timeout": "-1s"}]}`,
+			wantErr: errors.New(`logger "console": negative overflow timeout -1s`),
+		},
+		{
+			name:    "missing overflow timeout",
+			config:  `{"loggers": [{"mode": "console", "overflow": "block_timeout"}]}`,
+			wantErr: errors.New(`logger "console": overflow timeout is required by "block_timeout"`),
+		},
+		{
+			name:    "zero overflow timeout",
+			config:  `{"loggers": [{"mode": "console", "overflow": "block_timeout", "overflow_timeout": "0s"}]}`,
+			wantErr: errors.New(`logger "console": overflow timeout is required by "block_timeout"`),
+		},
+		{
+			name:    "invalid caller level",
+			config:  `{"loggers": [{"mode": "console", "caller_level": "verbose"}]}`,
+			wantErr: errors.New(`logger "console": caller level: unknown level "verbose"`),
+		},
+		{
+			name:    "unknown format",
+			config:  `{"loggers": [{"mode": "console", "options": {"format": "xml"}}]}`,
+			wantErr: errors.New(`logger "console": options: unknown format "xml"`),
+		},
+		{
+			name:    "unknown option",
+			config:  `{"loggers": [{"mode": "file", "options": {"file": "clog.log"}}]}`,
+			wantErr: errors.New(`logger "file": options: json: unknown field "file"`),
+		},
+		{
+			name:    "invalid interval",
+			config:  `{"loggers": [{"mode": "file", "options": {"interval": "hourly"}}]}`,
+			wantErr: errors.New(`logger "file": options: parse interval: time: invalid duration "hourly"`),
+		},
+		{
+			name:    "unknown location",
+			config:  `{"loggers": [{"mode": "file", "options": {"location": "Mars/Olympus_Mons"}}]}`,
+			wantErr: errors.New(`logger "file": options: load location: unknown time zone Mars/Olympus_Mons`),
+		},
+		{
+			name:    "missing slack URL",
+			config:  `{"loggers": [{"mode": "slack"}]}`,
+			wantErr: errors.New(`logger "slack": options: empty URL`),
+		},
+		{
+			name:    "missing discord URL",
+			config:  `{"loggers": [{"name": "alerts", "mode": "discord", "options": {"username": "bot"}}]}`,
+			wantErr: errors.New(`logger "alerts": options: empty URL`),
+		},
+		{
+			name:    "options of mode without decoder",
+			config:  `{"loggers": [{"mode": "TestParseConfig", "options": {"format": "json"}}]}`,
+			wantErr: errors.New(`logger "TestParseConfig": mode "TestParseConfig" takes no options`),
+		},
+		{
+			name:   "mode without decoder",
+			config: `{"loggers": [{"mode": "TestParseConfig", "options": null}]}`,
+		},
+		{
+			name:   "block timeout",
+			config: `{"loggers": [{"mode": "console", "overflow": "block_timeout", "overflow_timeout": "100ms"}]}`,
+		},
+		{
+			name:    "invalid webhook timeout",
+			config:  `{"loggers": [{"mode": "slack", "options": {"url": "https://slack.com", "timeout": "soon"}}]}`,
+			wantErr: errors.New(`logger "slack": options: parse timeout: time: invalid duration "soon"`),
+		},
+		{
+			name:    "negative webhook timeout",
+			config:  `{"loggers": [{"mode": "discord", "options": {"url": "https://discordapp.com", "timeout": "-1s"}}]}`,
+			wantErr: errors.New(`logger "discord": options: negative timeout -1s`),
+		},
+		{
+			name:    "duplicated name",
+			config:  `{"loggers": [{"mode": "console"}, {"name": "console", "mode": "file"}]}`,
+			wantErr: errors.New(`logger "console": duplicated name`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig(strings.NewReader(tt.config))
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
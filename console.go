@@ -1,7 +1,8 @@
 package clog
 
 import (
-	"log"
+	"fmt"
+	"io"
 
 	"github.com/fatih/color"
 )
@@ -19,20 +20,54 @@ var consoleColors = []func(a ...interface{}) string{
 type ConsoleConfig struct {
 	// Minimum logging level of messages to be processed.
 	Level Level
+	// Formatter to format messages. Leave nil to use colored text output.
+	Formatter Formatter
+}
+
+// ConsoleOption is a typed option of the console logger, it is applied on top
+// of the ConsoleConfig if any.
+type ConsoleOption func(*ConsoleConfig)
+
+// WithConsoleFormatter sets the formatter of the console logger.
+func WithConsoleFormatter(f Formatter) ConsoleOption {
+	return func(cfg *ConsoleConfig) {
+		cfg.Formatter = f
+	}
 }
 
 var _ Logger = (*consoleLogger)(nil)
 
 type consoleLogger struct {
 	*noopLogger
-	*log.Logger
+
+	formatter Formatter
+	out       io.Writer
 }
 
 func (l *consoleLogger) Write(m Messager) error {
-	l.Print(consoleColors[m.Level()](m.String()))
-	return nil
+	if l.formatter == nil {
+		_, err := fmt.Fprintln(l.out,
+			m.Time().Format(textTimeFormat),
+			withFields(consoleColors[m.Level()](m.String()), m.Fields()),
+		)
+		return err
+	}
+
+	p, err := l.formatter.Format(m)
+	if err != nil {
+		return fmt.Errorf("format: %v", err)
+	}
+
+	_, err = l.out.Write(p)
+	return err
 }
 
+// ModeConsole is the mode name of the console logger.
+const ModeConsole = "console"
+
+// Mode returns the mode name of the logger.
+func (*consoleLogger) Mode() string { return ModeConsole }
+
 // DefaultConsoleName is the default name for the console logger.
 const DefaultConsoleName = "console"
 
@@ -48,27 +83,57 @@ func NewConsoleWithName(name string, vs ...interface{}) error {
 	return New(name, ConsoleIniter(), vs...)
 }
 
+// ConsoleOptioner is a typed option accepted by NewConsoleWithOptions, it is
+// implemented by Option and ConsoleOption only.
+type ConsoleOptioner interface {
+	consoleOption()
+}
+
+func (Option) consoleOption()        {}
+func (ConsoleOption) consoleOption() {}
+
+// NewConsoleWithOptions initializes and appends a new console logger with given
+// name to the managed list. Unlike NewConsoleWithName, only typed options are
+// accepted so that mistakes are caught at compile time.
+func NewConsoleWithOptions(name string, opts ...ConsoleOptioner) error {
+	vs := make([]interface{}, len(opts))
+	for i := range opts {
+		vs[i] = opts[i]
+	}
+	return New(name, ConsoleIniter(), vs...)
+}
+
 // ConsoleIniter returns the initer for the console logger.
 func ConsoleIniter() Initer {
 	return func(name string, vs ...interface{}) (Logger, error) {
 		var cfg *ConsoleConfig
+		var opts []ConsoleOption
 		for i := range vs {
 			switch v := vs[i].(type) {
 			case ConsoleConfig:
 				cfg = &v
+			case ConsoleOption:
+				opts = append(opts, v)
+			case nil:
+			default:
+				return nil, fmt.Errorf("unrecognized option of type '%T'", v)
 			}
 		}
 
 		if cfg == nil {
 			cfg = &ConsoleConfig{}
 		}
+		for _, opt := range opts {
+			opt(cfg)
+		}
 
 		return &consoleLogger{
 			noopLogger: &noopLogger{
 				name:  name,
 				level: cfg.Level,
 			},
-			Logger: log.New(color.Output, "", log.Ldate|log.Ltime),
+			formatter: cfg.Formatter,
+			out:       color.Output,
 		}, nil
 	}
 }
@@ -1,6 +1,7 @@
 package clog
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,6 +37,18 @@ func Test_consoleLogger(t *testing.T) {
 			mode:    testName,
 			wantErr: nil,
 		},
+		{
+			name:    "config of other mode",
+			mode:    testName,
+			config:  FileConfig{},
+			wantErr: errors.New("initialize logger: unrecognized option of type 'clog.FileConfig'"),
+		},
+		{
+			name:    "unrecognized buffer size",
+			mode:    testName,
+			config:  uint(10),
+			wantErr: errors.New("initialize logger: unrecognized option of type 'uint'"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -44,8 +57,28 @@ func Test_consoleLogger(t *testing.T) {
 	}
 
 	assert.Equal(t, 2, mgr.len())
-	assert.Equal(t, DefaultConsoleName, mgr.loggers[0].Name())
-	assert.Equal(t, LevelInfo, mgr.loggers[0].Level())
-	assert.Equal(t, testName, mgr.loggers[1].Name())
-	assert.Equal(t, LevelTrace, mgr.loggers[1].Level())
+	assert.Equal(t, DefaultConsoleName, mgr.loggers()[0].Name())
+	assert.Equal(t, LevelInfo, mgr.loggers()[0].Level())
+	assert.Equal(t, testName, mgr.loggers()[1].Name())
+	assert.Equal(t, LevelTrace, mgr.loggers()[1].Level())
+}
+
+func TestNewConsoleWithOptions(t *testing.T) {
+	testName := "TestNewConsoleWithOptions"
+	defer Remove(testName)
+
+	err := NewConsoleWithOptions(testName,
+		WithBufferSize(10),
+		WithLevel(LevelWarn),
+		WithConsoleFormatter(JSONFormatter{}),
+	)
+	assert.Nil(t, err)
+
+	l, ok := mgr.lookup(testName)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, 10, cap(l.msgChan))
+	assert.Equal(t, LevelWarn, l.Level())
+	assert.Equal(t, JSONFormatter{}, l.Logger.(*consoleLogger).formatter)
 }
@@ -0,0 +1,147 @@
+package clog
+
+import (
+	"context"
+)
+
+type fieldsContextKey struct{}
+
+// NewContext returns a copy of ctx with given fields attached, fields that are
+// already attached to ctx are inherited unless being overwritten. Fields
+// attached to the context are added to every message written by the
+// "*Context" functions with the context.
+func NewContext(ctx context.Context, fields Fields) context.Context {
+	merged := make(Fields, len(fields))
+	for k, v := range FromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, fieldsContextKey{}, merged)
+}
+
+// FromContext returns fields attached to ctx by NewContext, or nil if none.
+// The returned value must not be modified.
+func FromContext(ctx context.Context) Fields {
+	fields, _ := ctx.Value(fieldsContextKey{}).(Fields)
+	return fields
+}
+
+// ContextExtractor extracts fields from a context, e.g. request ID, trace ID
+// and span ID set by other packages.
+type ContextExtractor func(ctx context.Context) Fields
+
+// RegisterContextExtractor registers an extractor whose returned fields are
+// added to every message written by the "*Context" methods.
+func (m *Manager) RegisterContextExtractor(fn ContextExtractor) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	extractors, _ := m.extractors.Load().([]ContextExtractor)
+	m.extractors.Store(append(extractors[:len(extractors):len(extractors)], fn))
+}
+
+// RegisterContextKey registers a context key whose value is added to every
+// message written by the "*Context" methods as a field with given name.
+func (m *Manager) RegisterContextKey(key interface{}, name string) {
+	m.RegisterContextExtractor(func(ctx context.Context) Fields {
+		v := ctx.Value(key)
+		if v == nil {
+			return nil
+		}
+		return Fields{name: v}
+	})
+}
+
+// RegisterContextExtractor registers an extractor for the default manager.
+func RegisterContextExtractor(fn ContextExtractor) {
+	mgr.RegisterContextExtractor(fn)
+}
+
+// RegisterContextKey registers a context key for the default manager.
+func RegisterContextKey(key interface{}, name string) {
+	mgr.RegisterContextKey(key, name)
+}
+
+// contextArgs returns format arguments with fields from the context prepended,
+// so fields passed explicitly take precedence.
+func (m *Manager) contextArgs(ctx context.Context, v []interface{}) []interface{} {
+	var fields Fields
+	merge := func(fs Fields) {
+		for k, v := range fs {
+			if fields == nil {
+				fields = make(Fields)
+			}
+			fields[k] = v
+		}
+	}
+
+	extractors, _ := m.extractors.Load().([]ContextExtractor)
+	for _, fn := range extractors {
+		merge(fn(ctx))
+	}
+	merge(FromContext(ctx))
+	if fields == nil {
+		return v
+	}
+	return append([]interface{}{fields}, v...)
+}
+
+// TraceContext writes formatted log in Trace level with fields from the
+// context.
+func (m *Manager) TraceContext(ctx context.Context, format string, v ...interface{}) {
+	m.write(LevelTrace, 3, format, m.contextArgs(ctx, v)...)
+}
+
+// InfoContext writes formatted log in Info level with fields from the context.
+func (m *Manager) InfoContext(ctx context.Context, format string, v ...interface{}) {
+	m.write(LevelInfo, 3, format, m.contextArgs(ctx, v)...)
+}
+
+// WarnContext writes formatted log in Warn level with fields from the context.
+func (m *Manager) WarnContext(ctx context.Context, format string, v ...interface{}) {
+	m.write(LevelWarn, 3, format, m.contextArgs(ctx, v)...)
+}
+
+// ErrorContext writes formatted log in Error level with fields from the
+// context.
+func (m *Manager) ErrorContext(ctx context.Context, format string, v ...interface{}) {
+	m.write(LevelError, 3, format, m.contextArgs(ctx, v)...)
+}
+
+// FatalContext writes formatted log in Fatal level with fields from the
+// context then exits.
+func (m *Manager) FatalContext(ctx context.Context, format string, v ...interface{}) {
+	m.write(LevelFatal, 3, format, m.contextArgs(ctx, v)...)
+	m.exit()
+}
+
+// TraceContext writes formatted log in Trace level with fields from the
+// context.
+func TraceContext(ctx context.Context, format string, v ...interface{}) {
+	mgr.write(LevelTrace, 3, format, mgr.contextArgs(ctx, v)...)
+}
+
+// InfoContext writes formatted log in Info level with fields from the context.
+func InfoContext(ctx context.Context, format string, v ...interface{}) {
+	mgr.write(LevelInfo, 3, format, mgr.contextArgs(ctx, v)...)
+}
+
+// WarnContext writes formatted log in Warn level with fields from the context.
+func WarnContext(ctx context.Context, format string, v ...interface{}) {
+	mgr.write(LevelWarn, 3, format, mgr.contextArgs(ctx, v)...)
+}
+
+// ErrorContext writes formatted log in Error level with fields from the
+// context.
+func ErrorContext(ctx context.Context, format string, v ...interface{}) {
+	mgr.write(LevelError, 3, format, mgr.contextArgs(ctx, v)...)
+}
+
+// FatalContext writes formatted log in Fatal level with fields from the
+// context then exits.
+func FatalContext(ctx context.Context, format string, v ...interface{}) {
+	mgr.write(LevelFatal, 3, format, mgr.contextArgs(ctx, v)...)
+	mgr.exit()
+}
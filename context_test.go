@@ -0,0 +1,55 @@
+package clog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, FromContext(ctx))
+
+	ctx1 := NewContext(ctx, Fields{"request_id": "abc", "user_id": 1})
+	ctx2 := NewContext(ctx1, Fields{"user_id": 2})
+	assert.Equal(t, Fields{"request_id": "abc", "user_id": 1}, FromContext(ctx1))
+	assert.Equal(t, Fields{"request_id": "abc", "user_id": 2}, FromContext(ctx2))
+}
+
+type traceIDContextKey struct{}
+
+func TestManager_InfoContext(t *testing.T) {
+	m := NewManager()
+	defer m.Stop()
+
+	c := make(chan Messager, 1)
+	assert.Nil(t, m.New("message", func(string, ...interface{}) (Logger, error) {
+		return &messageLogger{noopLogger: &noopLogger{name: "message"}, c: c}, nil
+	}))
+	m.RegisterContextKey(traceIDContextKey{}, "trace_id")
+	m.RegisterContextExtractor(func(ctx context.Context) Fields {
+		return Fields{"span_id": "span"}
+	})
+
+	ctx := context.WithValue(context.Background(), traceIDContextKey{}, "trace")
+	ctx = NewContext(ctx, Fields{"request_id": "abc", "user_id": 1})
+
+	m.InfoContext(ctx, "user %s login", "alice", Fields{"user_id": 2})
+	msg := <-c
+	assert.Equal(t, "[ INFO] user alice login", msg.String())
+	assert.Equal(t,
+		Fields{
+			"trace_id":   "trace",
+			"span_id":    "span",
+			"request_id": "abc",
+			"user_id":    2,
+		},
+		msg.Fields(),
+	)
+
+	m.ErrorContext(context.Background(), "failed")
+	msg = <-c
+	assert.Equal(t, Fields{"span_id": "span"}, msg.Fields())
+	assert.Contains(t, msg.Caller().File, "context_test.go")
+}
@@ -2,22 +2,29 @@ package clog
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
-	"strings"
 	"time"
 )
 
 type (
+	discordEmbedField struct {
+		Name   string `json:"name"`
+		Value  string `json:"value"`
+		Inline bool   `json:"inline"`
+	}
+
 	discordEmbed struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		Timestamp   string `json:"timestamp"`
-		Color       int    `json:"color"`
+		Title       string               `json:"title"`
+		Description string               `json:"description"`
+		Timestamp   string               `json:"timestamp"`
+		Color       int                  `json:"color"`
+		Fields      []*discordEmbedField `json:"fields,omitempty"`
 	}
 
 	discordPayload struct {
@@ -26,6 +33,9 @@ type (
 	}
 )
 
+// discordTimeFormat is the ISO8601 layout with milliseconds.
+const discordTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
 var (
 	discordTitles = []string{
 		"Trace",
@@ -59,9 +69,59 @@ type DiscordConfig struct {
 	// Colors for different levels, must have exact 5 elements in the order of
 	// Trace, Info, Warn, Error, and Fatal.
 	Colors []int
+	// HTTP client to send webhook requests. Leave nil to use the default client
+	// with a 30-second timeout.
+	Client *http.Client
+}
+
+// DiscordOption is a typed option of the Discord logger, it is applied on top
+// of the DiscordConfig if any.
+type DiscordOption func(*DiscordConfig)
+
+// WithDiscordURL sets the webhook URL of the Discord logger.
+func WithDiscordURL(url string) DiscordOption {
+	return func(cfg *DiscordConfig) {
+		cfg.URL = url
+	}
+}
+
+// WithDiscordUsername sets the username to be shown in the message.
+func WithDiscordUsername(username string) DiscordOption {
+	return func(cfg *DiscordConfig) {
+		cfg.Username = username
+	}
+}
+
+// WithDiscordTitles sets the titles for different levels of the Discord
+// logger, it must have exact 5 elements in the order of Trace, Info, Warn,
+// Error, and Fatal.
+func WithDiscordTitles(titles []string) DiscordOption {
+	return func(cfg *DiscordConfig) {
+		cfg.Titles = titles
+	}
+}
+
+// WithDiscordColors sets the colors for different levels of the Discord
+// logger, it must have exact 5 elements in the order of Trace, Info, Warn,
+// Error, and Fatal.
+func WithDiscordColors(colors []int) DiscordOption {
+	return func(cfg *DiscordConfig) {
+		cfg.Colors = colors
+	}
+}
+
+// WithDiscordClient sets the HTTP client to send webhook requests of the
+// Discord logger.
+func WithDiscordClient(client *http.Client) DiscordOption {
+	return func(cfg *DiscordConfig) {
+		cfg.Client = client
+	}
 }
 
-var _ Logger = (*discordLogger)(nil)
+var (
+	_ Logger  = (*discordLogger)(nil)
+	_ Starter = (*discordLogger)(nil)
+)
 
 type discordLogger struct {
 	*noopLogger
@@ -72,14 +132,33 @@ type discordLogger struct {
 	colors   []int
 
 	client *http.Client
+	// ctx is done once the logger is removed, replaced or stopped, it is used
+	// to stop waiting for retries.
+	ctx context.Context
+}
+
+// Start implements method of Starter interface.
+func (l *discordLogger) Start(ctx context.Context) error {
+	l.ctx = ctx
+	return nil
 }
 
 func (l *discordLogger) buildPayload(m Messager) (string, error) {
-	descPrefixLen := strings.Index(m.String(), "] ")
-	if descPrefixLen == -1 {
-		descPrefixLen = 0
-	} else {
-		descPrefixLen += 2
+	var embedFields []*discordEmbedField
+	if c := m.Caller(); c != nil {
+		embedFields = append(embedFields, &discordEmbedField{
+			Name:  "Caller",
+			Value: c.format(CallerPathShort),
+		})
+	}
+
+	fields := m.Fields()
+	for _, k := range fields.Keys() {
+		embedFields = append(embedFields, &discordEmbedField{
+			Name:   k,
+			Value:  fmt.Sprint(fields[k]),
+			Inline: true,
+		})
 	}
 
 	payload := discordPayload{
@@ -87,9 +166,10 @@ func (l *discordLogger) buildPayload(m Messager) (string, error) {
 		Embeds: []*discordEmbed{
 			{
 				Title:       l.titles[m.Level()],
-				Description: m.String()[descPrefixLen:],
-				Timestamp:   time.Now().Format(time.RFC3339),
+				Description: m.Text(),
+				Timestamp:   m.Time().Format(discordTimeFormat),
 				Color:       l.colors[m.Level()],
+				Fields:      embedFields,
 			},
 		},
 	}
@@ -127,6 +207,24 @@ func (l *discordLogger) postMessage(r io.Reader) (int64, error) {
 	return -1, nil
 }
 
+// wait blocks for the duration d, or returns the error of the context if the
+// logger is stopped in the meantime.
+func (l *discordLogger) wait(d time.Duration) error {
+	ctx := l.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 func (l *discordLogger) Write(m Messager) error {
 	payload, err := l.buildPayload(m)
 	if err != nil {
@@ -143,7 +241,9 @@ func (l *discordLogger) Write(m Messager) error {
 		}
 
 		if retryAfter > 0 {
-			time.Sleep(time.Duration(retryAfter) * time.Millisecond)
+			if err = l.wait(time.Duration(retryAfter) * time.Millisecond); err != nil {
+				return fmt.Errorf("wait for retry: %v", err)
+			}
 			continue
 		}
 
@@ -153,6 +253,12 @@ func (l *discordLogger) Write(m Messager) error {
 	return fmt.Errorf("gave up after %d retries", retryTimes)
 }
 
+// ModeDiscord is the mode name of the Discord logger.
+const ModeDiscord = "discord"
+
+// Mode returns the mode name of the logger.
+func (*discordLogger) Mode() string { return ModeDiscord }
+
 // DefaultDiscordName is the default name for the Discord logger.
 const DefaultDiscordName = "discord"
 
@@ -168,20 +274,54 @@ func NewDiscordWithName(name string, vs ...interface{}) error {
 	return New(name, DiscordIniter(), vs...)
 }
 
+// DiscordOptioner is a typed option accepted by NewDiscordWithOptions, it is
+// implemented by Option and DiscordOption only.
+type DiscordOptioner interface {
+	discordOption()
+}
+
+func (Option) discordOption()        {}
+func (DiscordOption) discordOption() {}
+
+// NewDiscordWithOptions initializes and appends a new Discord logger with given
+// name to the managed list. Unlike NewDiscordWithName, only typed options are
+// accepted so that mistakes are caught at compile time.
+func NewDiscordWithOptions(name string, opts ...DiscordOptioner) error {
+	vs := make([]interface{}, len(opts))
+	for i := range opts {
+		vs[i] = opts[i]
+	}
+	return New(name, DiscordIniter(), vs...)
+}
+
 // DiscordIniter returns the initer for the Discord logger.
 func DiscordIniter() Initer {
 	return func(name string, vs ...interface{}) (Logger, error) {
 		var cfg *DiscordConfig
+		var opts []DiscordOption
 		for i := range vs {
 			switch v := vs[i].(type) {
 			case DiscordConfig:
 				cfg = &v
+			case DiscordOption:
+				opts = append(opts, v)
+			case nil:
+			default:
+				return nil, fmt.Errorf("unrecognized option of type '%T'", v)
 			}
 		}
 
 		if cfg == nil {
-			return nil, fmt.Errorf("config object with the type '%T' not found", DiscordConfig{})
-		} else if cfg.URL == "" {
+			if len(opts) == 0 {
+				return nil, fmt.Errorf("config object with the type '%T' not found", DiscordConfig{})
+			}
+			cfg = &DiscordConfig{}
+		}
+		for _, opt := range opts {
+			opt(cfg)
+		}
+
+		if cfg.URL == "" {
 			return nil, errors.New("empty URL")
 		}
 
@@ -201,6 +341,11 @@ func DiscordIniter() Initer {
 			colors = cfg.Colors
 		}
 
+		client := webhookClient
+		if cfg.Client != nil {
+			client = cfg.Client
+		}
+
 		return &discordLogger{
 			noopLogger: &noopLogger{
 				name:  name,
@@ -210,7 +355,7 @@ func DiscordIniter() Initer {
 			username: cfg.Username,
 			titles:   titles,
 			colors:   colors,
-			client:   http.DefaultClient,
+			client:   client,
 		}, nil
 	}
 }
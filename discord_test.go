@@ -2,11 +2,13 @@ package clog
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -51,7 +53,23 @@ func Test_discordLogger(t *testing.T) {
 		{
 			name:    "invalid config",
 			config:  "random things",
-			wantErr: errors.New("initialize logger: config object with the type 'clog.DiscordConfig' not found"),
+			wantErr: errors.New("initialize logger: unrecognized option of type 'string'"),
+		},
+		{
+			name:    "config of other mode",
+			config:  SlackConfig{},
+			wantErr: errors.New("initialize logger: unrecognized option of type 'clog.SlackConfig'"),
+		},
+		{
+			name:    "typed options",
+			mode:    testName,
+			config:  WithDiscordURL("https://discordapp.com"),
+			wantErr: nil,
+		},
+		{
+			name:    "typed options without URL",
+			config:  WithDiscordUsername("bot"),
+			wantErr: errors.New("initialize logger: empty URL"),
 		},
 		{
 			name:    "invalid URL",
@@ -82,10 +100,10 @@ func Test_discordLogger(t *testing.T) {
 	}
 
 	assert.Equal(t, 2, mgr.len())
-	assert.Equal(t, DefaultDiscordName, mgr.loggers[0].Name())
-	assert.Equal(t, LevelInfo, mgr.loggers[0].Level())
-	assert.Equal(t, testName, mgr.loggers[1].Name())
-	assert.Equal(t, LevelTrace, mgr.loggers[1].Level())
+	assert.Equal(t, DefaultDiscordName, mgr.loggers()[0].Name())
+	assert.Equal(t, LevelInfo, mgr.loggers()[0].Level())
+	assert.Equal(t, testName, mgr.loggers()[1].Name())
+	assert.Equal(t, LevelTrace, mgr.loggers()[1].Level())
 }
 
 func Test_discordLogger_buildPayload(t *testing.T) {
@@ -106,7 +124,7 @@ func Test_discordLogger_buildPayload(t *testing.T) {
 				name: "trace",
 				msg: &message{
 					level: LevelTrace,
-					body:  "[TRACE] test message",
+					text:  "test message",
 				},
 				wantTitle: discordTitles[0],
 				wantDesc:  "test message",
@@ -116,7 +134,7 @@ func Test_discordLogger_buildPayload(t *testing.T) {
 				name: "info",
 				msg: &message{
 					level: LevelInfo,
-					body:  "[ INFO] test message",
+					text:  "test message",
 				},
 				wantTitle: discordTitles[1],
 				wantDesc:  "test message",
@@ -126,7 +144,7 @@ func Test_discordLogger_buildPayload(t *testing.T) {
 				name: "warn",
 				msg: &message{
 					level: LevelWarn,
-					body:  "[ WARN] test message",
+					text:  "test message",
 				},
 				wantTitle: discordTitles[2],
 				wantDesc:  "test message",
@@ -136,7 +154,7 @@ func Test_discordLogger_buildPayload(t *testing.T) {
 				name: "error",
 				msg: &message{
 					level: LevelError,
-					body:  "[ERROR] test message",
+					text:  "test message",
 				},
 				wantTitle: discordTitles[3],
 				wantDesc:  "test message",
@@ -146,7 +164,7 @@ func Test_discordLogger_buildPayload(t *testing.T) {
 				name: "fatal",
 				msg: &message{
 					level: LevelFatal,
-					body:  "[FATAL] test message",
+					text:  "test message",
 				},
 				wantTitle: discordTitles[4],
 				wantDesc:  "test message",
@@ -187,7 +205,7 @@ func Test_discordLogger_buildPayload(t *testing.T) {
 				name: "trace",
 				msg: &message{
 					level: LevelTrace,
-					body:  "[TRACE] test message",
+					text:  "test message",
 				},
 				wantTitle: l.titles[0],
 				wantDesc:  "test message",
@@ -197,7 +215,7 @@ func Test_discordLogger_buildPayload(t *testing.T) {
 				name: "info",
 				msg: &message{
 					level: LevelInfo,
-					body:  "[ INFO] test message",
+					text:  "test message",
 				},
 				wantTitle: l.titles[1],
 				wantDesc:  "test message",
@@ -207,7 +225,7 @@ func Test_discordLogger_buildPayload(t *testing.T) {
 				name: "warn",
 				msg: &message{
 					level: LevelWarn,
-					body:  "[ WARN] test message",
+					text:  "test message",
 				},
 				wantTitle: l.titles[2],
 				wantDesc:  "test message",
@@ -217,7 +235,7 @@ func Test_discordLogger_buildPayload(t *testing.T) {
 				name: "error",
 				msg: &message{
 					level: LevelError,
-					body:  "[ERROR] test message",
+					text:  "test message",
 				},
 				wantTitle: l.titles[3],
 				wantDesc:  "test message",
@@ -227,7 +245,7 @@ func Test_discordLogger_buildPayload(t *testing.T) {
 				name: "fatal",
 				msg: &message{
 					level: LevelFatal,
-					body:  "[FATAL] test message",
+					text:  "test message",
 				},
 				wantTitle: l.titles[4],
 				wantDesc:  "test message",
@@ -238,7 +256,7 @@ func Test_discordLogger_buildPayload(t *testing.T) {
 				name: "trace",
 				msg: &message{
 					level: LevelTrace,
-					body:  "test message",
+					text:  "test message",
 				},
 				wantTitle: l.titles[0],
 				wantDesc:  "test message",
@@ -263,6 +281,36 @@ func Test_discordLogger_buildPayload(t *testing.T) {
 	})
 }
 
+func Test_discordLogger_buildPayload_fields(t *testing.T) {
+	l := &discordLogger{
+		titles: discordTitles,
+		colors: discordColors,
+	}
+
+	payload, err := l.buildPayload(&message{
+		level:  LevelInfo,
+		text:   "test message",
+		fields: Fields{"user_id": 42, "ip": "127.0.0.1"},
+		caller: &Caller{File: "/src/example/main.go", Line: 64, Function: "example.main"},
+		time:   time.Date(2020, 11, 22, 8, 30, 15, 123456789, time.UTC),
+	})
+	assert.Nil(t, err)
+
+	obj := &discordPayload{}
+	assert.Nil(t, json.Unmarshal([]byte(payload), obj))
+	assert.Len(t, obj.Embeds, 1)
+	assert.Equal(t, "test message", obj.Embeds[0].Description)
+	assert.Equal(t, "2020-11-22T08:30:15.123Z", obj.Embeds[0].Timestamp)
+	assert.Equal(t,
+		[]*discordEmbedField{
+			{Name: "Caller", Value: "/src/example/main.go:64 main()"},
+			{Name: "ip", Value: "127.0.0.1", Inline: true},
+			{Name: "user_id", Value: "42", Inline: true},
+		},
+		obj.Embeds[0].Fields,
+	)
+}
+
 func Test_discordLogger_postMessage(t *testing.T) {
 	l := &discordLogger{
 		client: &http.Client{
@@ -324,3 +372,75 @@ func Test_discordLogger_postMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestNewDiscordWithOptions(t *testing.T) {
+	testName := "TestNewDiscordWithOptions"
+	defer Remove(testName)
+
+	assert.Equal(t,
+		errors.New("initialize logger: empty URL"),
+		NewDiscordWithOptions(testName, WithDiscordUsername("bot")),
+	)
+
+	err := NewDiscordWithOptions(testName,
+		WithBufferSize(10),
+		WithLevel(LevelError),
+		WithDiscordURL("https://discordapp.com"),
+		WithDiscordUsername("bot"),
+	)
+	assert.Nil(t, err)
+
+	l, ok := mgr.lookup(testName)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, 10, cap(l.msgChan))
+	assert.Equal(t, LevelError, l.Level())
+	dl := l.Logger.(*discordLogger)
+	assert.Equal(t, "https://discordapp.com", dl.url)
+	assert.Equal(t, "bot", dl.username)
+}
+
+func Test_discordLogger_Write_stop(t *testing.T) {
+	l := &discordLogger{
+		noopLogger: &noopLogger{},
+		titles:     discordTitles,
+		colors:     discordColors,
+		client: &http.Client{
+			Transport: roundTripFunc(func(req *http.Request) *http.Response {
+				return &http.Response{
+					StatusCode: 429,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"retry_after": 3600000}`)),
+					Header:     make(http.Header),
+				}
+			}),
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.Nil(t, l.Start(ctx))
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- l.Write(newMessage(LevelInfo, 0, "rate limited"))
+	}()
+	cancel()
+
+	select {
+	case err := <-errc:
+		assert.Equal(t, errors.New("wait for retry: context canceled"), err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Write is not interrupted by the stop of the logger")
+	}
+}
+
+func TestDiscordIniter_client(t *testing.T) {
+	l, err := DiscordIniter()("TestDiscordIniter_client", WithDiscordURL("https://discordapp.com"))
+	assert.Nil(t, err)
+	assert.Same(t, webhookClient, l.(*discordLogger).client)
+
+	client := &http.Client{Timeout: time.Second}
+	l, err = DiscordIniter()("TestDiscordIniter_client", WithDiscordURL("https://discordapp.com"), WithDiscordClient(client))
+	assert.Nil(t, err)
+	assert.Same(t, client, l.(*discordLogger).client)
+}
@@ -0,0 +1,157 @@
+package clog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EnvError is returned by FromEnv when any environment variable is malformed,
+// it contains an error for every malformed variable.
+type EnvError struct {
+	Errors []error
+}
+
+func (e *EnvError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i := range e.Errors {
+		msgs[i] = e.Errors[i].Error()
+	}
+	return "malformed environment variables: " + strings.Join(msgs, "; ")
+}
+
+// FromEnv initializes and appends loggers to the managed list according to
+// the following environment variables, empty values are treated as unset:
+//
+//	CLOG_LEVEL          Minimum logging level of all loggers, e.g. "info". Default is "trace".
+//	CLOG_FORMAT         Format of the console and file loggers, one of "text", "json" and "logfmt".
+//	CLOG_BUFFER_SIZE    Buffer size of all loggers.
+//	CLOG_CONSOLE        Whether to set up the console logger. Default is true.
+//	CLOG_FILE           File name to set up the file logger.
+//	CLOG_FILE_MAX_SIZE  Maximum size in bytes of the file before rotation.
+//	CLOG_SLACK_URL      Webhook URL to set up the Slack logger.
+//	CLOG_DISCORD_URL    Webhook URL to set up the Discord logger.
+//
+// All variables are validated before any logger is initialized, and an
+// *EnvError that reports every malformed variable is returned if any.
+func (m *Manager) FromEnv() error {
+	var errs []error
+	invalid := func(key string, err error) {
+		errs = append(errs, fmt.Errorf("%s: %v", key, err))
+	}
+
+	var err error
+	var level Level
+	if v := os.Getenv("CLOG_LEVEL"); v != "" {
+		level, err = ParseLevel(v)
+		if err != nil {
+			invalid("CLOG_LEVEL", err)
+		}
+	}
+
+	formatter, err := formatterByName(os.Getenv("CLOG_FORMAT"))
+	if err != nil {
+		invalid("CLOG_FORMAT", err)
+	}
+
+	var bufferSize int
+	if v := os.Getenv("CLOG_BUFFER_SIZE"); v != "" {
+		bufferSize, err = strconv.Atoi(v)
+		if err != nil {
+			invalid("CLOG_BUFFER_SIZE", err)
+		} else if bufferSize < 0 {
+			invalid("CLOG_BUFFER_SIZE", fmt.Errorf("negative buffer size %d", bufferSize))
+		}
+	}
+
+	console := true
+	if v := os.Getenv("CLOG_CONSOLE"); v != "" {
+		console, err = strconv.ParseBool(v)
+		if err != nil {
+			invalid("CLOG_CONSOLE", err)
+		}
+	}
+
+	var maxSize int64
+	if v := os.Getenv("CLOG_FILE_MAX_SIZE"); v != "" {
+		maxSize, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			invalid("CLOG_FILE_MAX_SIZE", err)
+		} else if maxSize <= 0 {
+			invalid("CLOG_FILE_MAX_SIZE", fmt.Errorf("non-positive size %d", maxSize))
+		} else if os.Getenv("CLOG_FILE") == "" {
+			invalid("CLOG_FILE_MAX_SIZE", errors.New("CLOG_FILE is not set"))
+		}
+	}
+
+	if len(errs) > 0 {
+		return &EnvError{Errors: errs}
+	}
+
+	type logger struct {
+		name   string
+		initer Initer
+		config interface{}
+	}
+	var loggers []logger
+	if console {
+		loggers = append(loggers, logger{
+			name:   DefaultConsoleName,
+			initer: ConsoleIniter(),
+			config: ConsoleConfig{
+				Level:     level,
+				Formatter: formatter,
+			},
+		})
+	}
+	if filename := os.Getenv("CLOG_FILE"); filename != "" {
+		loggers = append(loggers, logger{
+			name:   DefaultFileName,
+			initer: FileIniter(),
+			config: FileConfig{
+				Level:     level,
+				Filename:  filename,
+				Formatter: formatter,
+				FileRotationConfig: FileRotationConfig{
+					Rotate:  maxSize > 0,
+					MaxSize: maxSize,
+				},
+			},
+		})
+	}
+	if url := os.Getenv("CLOG_SLACK_URL"); url != "" {
+		loggers = append(loggers, logger{
+			name:   DefaultSlackName,
+			initer: SlackIniter(),
+			config: SlackConfig{
+				Level: level,
+				URL:   url,
+			},
+		})
+	}
+	if url := os.Getenv("CLOG_DISCORD_URL"); url != "" {
+		loggers = append(loggers, logger{
+			name:   DefaultDiscordName,
+			initer: DiscordIniter(),
+			config: DiscordConfig{
+				Level: level,
+				URL:   url,
+			},
+		})
+	}
+
+	for _, l := range loggers {
+		if err = m.New(l.name, l.initer, bufferSize, l.config); err != nil {
+			return fmt.Errorf("logger %q: %v", l.name, err)
+		}
+	}
+	return nil
+}
+
+// FromEnv initializes and appends loggers to the managed list of the default
+// manager according to environment variables.
+func FromEnv() error {
+	return mgr.FromEnv()
+}
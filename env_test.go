@@ -0,0 +1,107 @@
+package clog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setEnv sets environment variables and returns a function to unset them.
+func setEnv(t *testing.T, env map[string]string) func() {
+	for k, v := range env {
+		assert.Nil(t, os.Setenv(k, v))
+	}
+	return func() {
+		for k := range env {
+			_ = os.Unsetenv(k)
+		}
+	}
+}
+
+func TestManager_FromEnv(t *testing.T) {
+	t.Run("malformed variables", func(t *testing.T) {
+		defer setEnv(t, map[string]string{
+			"CLOG_LEVEL":         "verbose",
+			"CLOG_FORMAT":        "xml",
+			"CLOG_BUFFER_SIZE":   "-1",
+			"CLOG_CONSOLE":       "maybe",
+			"CLOG_FILE_MAX_SIZE": "10MB",
+		})()
+
+		m := NewManager()
+		defer m.Stop()
+
+		err := m.FromEnv()
+		assert.IsType(t, &EnvError{}, err)
+		assert.Equal(t, `malformed environment variables: `+
+			`CLOG_LEVEL: unknown level "verbose"; `+
+			`CLOG_FORMAT: unknown format "xml"; `+
+			`CLOG_BUFFER_SIZE: negative buffer size -1; `+
+			`CLOG_CONSOLE: strconv.ParseBool: parsing "maybe": invalid syntax; `+
+			`CLOG_FILE_MAX_SIZE: strconv.ParseInt: parsing "10MB": invalid syntax`,
+			err.Error())
+		assert.Len(t, err.(*EnvError).Errors, 5)
+		assert.Equal(t, 0, m.len())
+	})
+
+	t.Run("max size without file", func(t *testing.T) {
+		defer setEnv(t, map[string]string{
+			"CLOG_FILE_MAX_SIZE": "1024",
+		})()
+
+		m := NewManager()
+		defer m.Stop()
+
+		err := m.FromEnv()
+		assert.Equal(t, "malformed environment variables: CLOG_FILE_MAX_SIZE: CLOG_FILE is not set", err.Error())
+	})
+
+	t.Run("all loggers", func(t *testing.T) {
+		_ = os.MkdirAll("test", os.ModePerm)
+		defer os.RemoveAll("test")
+
+		filename := filepath.Join("test", "env.log")
+		defer setEnv(t, map[string]string{
+			"CLOG_LEVEL":         "warn",
+			"CLOG_FORMAT":        "json",
+			"CLOG_BUFFER_SIZE":   "10",
+			"CLOG_FILE":          filename,
+			"CLOG_FILE_MAX_SIZE": "1024",
+			"CLOG_SLACK_URL":     "https://slack.example.com",
+			"CLOG_DISCORD_URL":   "https://discord.example.com",
+		})()
+
+		m := NewManager()
+		defer m.Stop()
+
+		assert.Nil(t, m.FromEnv())
+		assert.Equal(t, []string{"console", "file", "slack", "discord"}, loggerNames(m))
+		for _, l := range m.loggers() {
+			assert.Equal(t, LevelWarn, l.Level())
+			assert.Equal(t, 10, cap(l.msgChan))
+		}
+
+		l, _ := m.lookup("console")
+		assert.Equal(t, JSONFormatter{}, l.Logger.(*consoleLogger).formatter)
+
+		l, _ = m.lookup("file")
+		fl := l.Logger.(*fileLogger)
+		assert.Equal(t, filename, fl.filename)
+		assert.Equal(t, JSONFormatter{}, fl.formatter)
+		assert.Equal(t, FileRotationConfig{Rotate: true, MaxSize: 1024}, fl.rotationConfig)
+	})
+
+	t.Run("without console", func(t *testing.T) {
+		defer setEnv(t, map[string]string{
+			"CLOG_CONSOLE": "false",
+		})()
+
+		m := NewManager()
+		defer m.Stop()
+
+		assert.Nil(t, m.FromEnv())
+		assert.Equal(t, 0, m.len())
+	})
+}
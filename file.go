@@ -2,34 +2,67 @@ package clog
 
 import (
 	"bytes"
+	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
-const (
-	simpleDateFormat = "2006-01-02"
-	logPrefixLength  = len("2017/02/06 21:20:08 ")
-)
+const simpleDateFormat = "2006-01-02"
 
 // FileRotationConfig represents rotation related configurations for file mode logger.
 // All the settings can take effect at the same time, remain zero values to disable them.
 type FileRotationConfig struct {
 	// Do rotation for output files.
 	Rotate bool
-	// Rotate on daily basis.
+	// Rotate on daily basis, it is the same as an Interval of 24 hours.
 	Daily bool
+	// Rotate at every boundary of the interval aligned to the wall clock, e.g.
+	// time.Hour rotates at the start of every hour, and 7*24*time.Hour rotates
+	// at the start of every Monday. It must be a multiple of a second.
+	Interval time.Duration
+	// Time zone of the wall clock that intervals are aligned to, it is also
+	// used to format and parse the time in rotated file names. Default is
+	// time.Local.
+	Location *time.Location
 	// Maximum size in bytes of file for a rotation.
 	MaxSize int64
 	// Maximum number of lines for a rotation.
 	MaxLines int64
-	// Maximum lifetime of a output file in days.
+	// Maximum lifetime of a output file in days, based on the date in the name
+	// of the rotated file.
 	MaxDays int64
+	// Maximum number of rotated files to keep, the oldest ones are deleted.
+	MaxBackups int
+	// Maximum total size in bytes of the file being written and rotated files,
+	// the oldest rotated files are deleted until the total size fits.
+	MaxTotalSize int64
+	// Compress rotated files with gzip in the background, compressed files
+	// have the ".gz" suffix.
+	Compress bool
+	// Pattern of rotated file names in the directory of the file, e.g.
+	// "app-{date}-{seq}.log" or "app.{timestamp}.log". It must contain {date}
+	// or {timestamp}, and {seq} is replaced by a three-digit sequence starting
+	// from 001, which is required when MaxSize or MaxLines is set. Default is
+	// "<filename>.{date}" with ".<seq>" appended from the second file of the
+	// same date.
+	FilenamePattern string
+	// Time layout of {date} in rotated file names, it must contain the year,
+	// month and day. Default is "2006-01-02".
+	DateFormat string
+	// Time layout of {timestamp} in rotated file names, it must contain the
+	// year, month and day unless the pattern also contains {date}. Default is
+	// "20060102150405".
+	TimestampFormat string
 }
 
 // FileConfig is the config object for the file logger.
@@ -38,10 +71,38 @@ type FileConfig struct {
 	Level Level
 	// File name to output messages.
 	Filename string
+	// Formatter to format messages. Default is TextFormatter.
+	Formatter Formatter
 	// Rotation related configurations.
 	FileRotationConfig
 }
 
+// FileOption is a typed option of the file logger, it is applied on top of the
+// FileConfig if any.
+type FileOption func(*FileConfig)
+
+// WithFilename sets the file name to output messages.
+func WithFilename(filename string) FileOption {
+	return func(cfg *FileConfig) {
+		cfg.Filename = filename
+	}
+}
+
+// WithFileFormatter sets the formatter of the file logger.
+func WithFileFormatter(f Formatter) FileOption {
+	return func(cfg *FileConfig) {
+		cfg.Formatter = f
+	}
+}
+
+// WithFileRotation sets the rotation related configurations of the file
+// logger.
+func WithFileRotation(rotation FileRotationConfig) FileOption {
+	return func(cfg *FileConfig) {
+		cfg.FileRotationConfig = rotation
+	}
+}
+
 var _ Logger = (*fileLogger)(nil)
 
 type fileLogger struct {
@@ -52,15 +113,27 @@ type fileLogger struct {
 	*noopLogger
 
 	filename       string
+	formatter      Formatter
 	rotationConfig FileRotationConfig
+	naming         *rotateNaming
+
+	// mu protects the file and rotation metadata from being reopened or
+	// closed while writing.
+	mu sync.Mutex
 
 	// Rotation metadata
 	file         *os.File
-	openDay      int
+	openPeriod   time.Time
 	currentSize  int64
 	currentLines int64
 
-	*log.Logger
+	// compressing tracks rotated files being compressed in the background,
+	// compressingFiles holds their paths to be skipped by retention, and
+	// compressErrs collects their errors to be reported by Flush or Close.
+	compressing      sync.WaitGroup
+	compressMu       sync.Mutex
+	compressingFiles map[string]bool
+	compressErrs     []string
 }
 
 var newLineBytes = []byte("\n")
@@ -70,8 +143,6 @@ func (l *fileLogger) initFile() (err error) {
 	if err != nil {
 		return fmt.Errorf("open file %q: %v", l.filename, err)
 	}
-
-	l.Logger = log.New(l.file, "", log.Ldate|log.Ltime)
 	return nil
 }
 
@@ -81,130 +152,640 @@ func isExist(path string) bool {
 	return err == nil || os.IsExist(err)
 }
 
-// rotateFilename returns next available rotate filename in given date.
-func rotateFilename(filename, date string) string {
-	filename = fmt.Sprintf("%s.%s", filename, date)
-	if !isExist(filename) {
-		return filename
+// isRotateExist returns true if the rotated file or its compressed file
+// exists.
+func isRotateExist(filename string) bool {
+	return isExist(filename) || isExist(filename+compressSuffix)
+}
+
+const (
+	defaultRotateTimestampFormat = "20060102150405"
+	// maxRotateSeq is the maximum sequence of rotated files with the same date
+	// or timestamp.
+	maxRotateSeq = 999
+)
+
+var rotatePlaceholderRegexp = regexp.MustCompile(`\{(date|seq|timestamp)\}`)
+
+// rotateNameToken is either a literal part or a placeholder of the rotated
+// filename pattern.
+type rotateNameToken struct {
+	literal     string
+	placeholder string
+}
+
+// rotateNaming generates and parses names of rotated files.
+type rotateNaming struct {
+	tokens []rotateNameToken
+	// implicitSeq indicates the sequence is appended as ".<seq>" when it is not
+	// zero, which is the default naming scheme.
+	implicitSeq  bool
+	hasSeq       bool
+	hasDate      bool
+	hasTimestamp bool
+	// timestampHasDate indicates the layout of {timestamp} contains the full
+	// date, otherwise the date is taken from {date}.
+	timestampHasDate bool
+	dateFormat       string
+	timestampFormat  string
+	location         *time.Location
+
+	re *regexp.Regexp
+	// groups are placeholders of submatches of the regexp in order.
+	groups []string
+}
+
+func newRotateNaming(filename string, cfg FileRotationConfig) (*rotateNaming, error) {
+	n := &rotateNaming{
+		dateFormat:      cfg.DateFormat,
+		timestampFormat: cfg.TimestampFormat,
+		location:        cfg.Location,
+	}
+	if n.location == nil {
+		n.location = time.Local
+	}
+	if n.dateFormat == "" {
+		n.dateFormat = simpleDateFormat
+	}
+	if n.timestampFormat == "" {
+		n.timestampFormat = defaultRotateTimestampFormat
+	}
+	for _, format := range []string{n.dateFormat, n.timestampFormat} {
+		if strings.ContainsAny(time.Now().Format(format), `/\`) {
+			return nil, fmt.Errorf("time format %q must not contain path separators", format)
+		}
 	}
 
-	format := filename + ".%03d"
-	for i := 1; i < 1000; i++ {
-		filename := fmt.Sprintf(format, i)
-		if !isExist(filename) {
-			return filename
+	pattern := cfg.FilenamePattern
+	if pattern == "" {
+		n.tokens = []rotateNameToken{
+			{literal: filepath.Base(filename) + "."},
+			{placeholder: "date"},
+		}
+		n.implicitSeq = true
+		n.hasDate = true
+	} else {
+		if strings.ContainsAny(pattern, `/\`) {
+			return nil, fmt.Errorf("filename pattern %q must not contain path separators", pattern)
+		}
+
+		last := 0
+		for _, loc := range rotatePlaceholderRegexp.FindAllStringSubmatchIndex(pattern, -1) {
+			if loc[0] > last {
+				n.tokens = append(n.tokens, rotateNameToken{literal: pattern[last:loc[0]]})
+			}
+			placeholder := pattern[loc[2]:loc[3]]
+			n.tokens = append(n.tokens, rotateNameToken{placeholder: placeholder})
+			switch placeholder {
+			case "date":
+				n.hasDate = true
+			case "timestamp":
+				n.hasTimestamp = true
+			case "seq":
+				n.hasSeq = true
+			}
+			last = loc[1]
+		}
+		if last < len(pattern) {
+			n.tokens = append(n.tokens, rotateNameToken{literal: pattern[last:]})
+		}
+
+		if !n.hasDate && !n.hasTimestamp {
+			return nil, fmt.Errorf("filename pattern %q must contain {date} or {timestamp}", pattern)
+		} else if !n.hasSeq && (cfg.MaxSize > 0 || cfg.MaxLines > 0) {
+			// Otherwise the second rotation in the same date or timestamp would
+			// fail.
+			return nil, fmt.Errorf("filename pattern %q must contain {seq} when rotating by size or lines", pattern)
 		}
 	}
 
-	panic("too many log files for yesterday, already reached 999")
+	// The date of rotated files must be parsed back for retention.
+	n.timestampHasDate = hasFullDate(n.timestampFormat)
+	if n.hasDate && !hasFullDate(n.dateFormat) {
+		return nil, fmt.Errorf("date format %q must contain the year, month and day", n.dateFormat)
+	} else if !n.hasDate && n.hasTimestamp && !n.timestampHasDate {
+		return nil, fmt.Errorf("timestamp format %q must contain the year, month and day without {date}", n.timestampFormat)
+	}
+
+	expr := "^"
+	for _, tok := range n.tokens {
+		switch tok.placeholder {
+		case "":
+			expr += regexp.QuoteMeta(tok.literal)
+			continue
+		case "seq":
+			expr += `(\d{3})`
+		default:
+			expr += `(.+?)`
+		}
+		n.groups = append(n.groups, tok.placeholder)
+	}
+	if n.implicitSeq {
+		expr += `(?:\.(\d{3}))?`
+		n.groups = append(n.groups, "seq")
+	}
+	n.re = regexp.MustCompile(expr + "$")
+	return n, nil
 }
 
-func (l *fileLogger) deleteOutdatedFiles() error {
-	return filepath.Walk(filepath.Dir(l.filename), func(path string, fi os.FileInfo, _ error) error {
-		if !fi.IsDir() &&
-			fi.ModTime().Before(time.Now().Add(-24*time.Hour*time.Duration(l.rotationConfig.MaxDays))) &&
-			strings.HasPrefix(filepath.Base(path), filepath.Base(l.filename)) {
-			return os.Remove(path)
+// format returns the rotated file name of given time and sequence.
+func (n *rotateNaming) format(t time.Time, seq int) string {
+	t = t.In(n.location)
+	var buf bytes.Buffer
+	for _, tok := range n.tokens {
+		switch tok.placeholder {
+		case "":
+			buf.WriteString(tok.literal)
+		case "date":
+			buf.WriteString(t.Format(n.dateFormat))
+		case "timestamp":
+			buf.WriteString(t.Format(n.timestampFormat))
+		case "seq":
+			fmt.Fprintf(&buf, "%03d", seq)
+		}
+	}
+	if n.implicitSeq && seq > 0 {
+		fmt.Fprintf(&buf, ".%03d", seq)
+	}
+	return buf.String()
+}
+
+// next returns the next rotated file name in given directory for given time,
+// the sequence follows the largest existing one of the same date or timestamp,
+// so the newest rotated file always has the largest sequence even if older
+// ones have been deleted. It returns an error if the sequence is exhausted.
+func (n *rotateNaming) next(dir string, t time.Time) (string, error) {
+	if !n.implicitSeq && !n.hasSeq {
+		filename := filepath.Join(dir, n.format(t, 0))
+		if isRotateExist(filename) {
+			return "", fmt.Errorf("rotated file %q already exists", filename)
+		}
+		return filename, nil
+	}
+
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("read directory: %v", err)
+	}
+
+	key := n.format(t, 0)
+	maxSeq := -1
+	for _, fi := range fis {
+		pt, seq, ok := n.parse(fi.Name())
+		if ok && seq > maxSeq && n.format(pt, 0) == key {
+			maxSeq = seq
+		}
+	}
+
+	seq := maxSeq + 1
+	if !n.implicitSeq && seq < 1 {
+		seq = 1
+	}
+	if seq > maxRotateSeq {
+		return "", fmt.Errorf("too many rotated files of %q, already reached %d", key, maxRotateSeq)
+	}
+	return filepath.Join(dir, n.format(t, seq)), nil
+}
+
+// parse parses the name of a rotated file, either compressed or not, it
+// returns false if the name does not match the pattern. The time is taken from
+// {timestamp} if present, with the date from {date} if the layout of
+// {timestamp} has no date.
+func (n *rotateNaming) parse(name string) (t time.Time, seq int, ok bool) {
+	m := n.re.FindStringSubmatch(strings.TrimSuffix(name, compressSuffix))
+	if m == nil {
+		return time.Time{}, 0, false
+	}
+
+	var date, timestamp time.Time
+	for i, placeholder := range n.groups {
+		v := m[i+1]
+		switch placeholder {
+		case "date":
+			date, ok = parseTimeStrict(n.dateFormat, v, n.location)
+		case "timestamp":
+			timestamp, ok = parseTimeStrict(n.timestampFormat, v, n.location)
+		case "seq":
+			if v == "" && n.implicitSeq {
+				continue
+			}
+			seq, _ = strconv.Atoi(v)
+			ok = seq >= 1
+		}
+		if !ok {
+			return time.Time{}, 0, false
+		}
+	}
+
+	if !n.hasTimestamp {
+		return date, seq, true
+	} else if n.timestampHasDate {
+		return timestamp, seq, true
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(),
+		timestamp.Hour(), timestamp.Minute(), timestamp.Second(), timestamp.Nanosecond(), n.location), seq, true
+}
+
+// hasFullDate returns true if the time layout contains the year, month and
+// day.
+func hasFullDate(layout string) bool {
+	ref := time.Date(2017, 3, 5, 13, 14, 15, 0, time.UTC)
+	t, err := time.Parse(layout, ref.Format(layout))
+	return err == nil && t.Year() == ref.Year() && t.Month() == ref.Month() && t.Day() == ref.Day()
+}
+
+// parseTimeStrict parses the value in given time zone with given layout, it
+// returns false if the value is not exactly what the layout formats.
+func parseTimeStrict(layout, value string, loc *time.Location) (time.Time, bool) {
+	t, err := time.ParseInLocation(layout, value, loc)
+	if err != nil || t.Format(layout) != value {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+const compressSuffix = ".gz"
+
+// compressFile compresses the file with gzip into a file with the ".gz"
+// suffix, then removes the original file. The compressed file keeps the
+// modification time of the original file.
+func compressFile(filename string) (err error) {
+	src, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	fi, err := src.Stat()
+	if err != nil {
+		return fmt.Errorf("stat: %v", err)
+	}
+
+	dstname := filename + compressSuffix
+	dst, err := os.OpenFile(dstname, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(dstname)
 		}
+	}()
+
+	gw := gzip.NewWriter(dst)
+	if _, err = io.Copy(gw, src); err != nil {
+		return fmt.Errorf("compress: %v", err)
+	}
+	if err = gw.Close(); err != nil {
+		return fmt.Errorf("compress: %v", err)
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	_ = os.Chtimes(dstname, fi.ModTime(), fi.ModTime())
+
+	// The original file may have been deleted as outdated in the meantime.
+	if err = os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// compress compresses the rotated file in the background if enabled, it never
+// compresses the file being written.
+func (l *fileLogger) compress(rotated string) {
+	if !l.rotationConfig.Compress || rotated == l.filename {
+		return
+	}
+
+	l.compressMu.Lock()
+	if l.compressingFiles == nil {
+		l.compressingFiles = make(map[string]bool)
+	}
+	l.compressingFiles[rotated] = true
+	l.compressMu.Unlock()
+
+	l.compressing.Add(1)
+	go func() {
+		defer l.compressing.Done()
+
+		err := compressFile(rotated)
+
+		l.compressMu.Lock()
+		defer l.compressMu.Unlock()
+		delete(l.compressingFiles, rotated)
+		if err != nil {
+			l.compressErrs = append(l.compressErrs, fmt.Sprintf("compress %q: %v", rotated, err))
+		}
+	}()
+}
+
+// isCompressing returns true if the rotated file is being compressed in the
+// background.
+func (l *fileLogger) isCompressing(path string) bool {
+	l.compressMu.Lock()
+	defer l.compressMu.Unlock()
+	return l.compressingFiles[path]
+}
+
+// compressError returns an error of all compression errors since last call,
+// or nil if none.
+func (l *fileLogger) compressError() error {
+	l.compressMu.Lock()
+	defer l.compressMu.Unlock()
+
+	if len(l.compressErrs) == 0 {
 		return nil
+	}
+	err := errors.New(strings.Join(l.compressErrs, "; "))
+	l.compressErrs = nil
+	return err
+}
+
+type rotatedFile struct {
+	path string
+	date time.Time
+	seq  int
+	size int64
+}
+
+// rotatedFiles returns rotated files of the log file, either compressed or
+// not, sorted from the newest to the oldest by the time and sequence in their
+// names. Only files directly in the directory of the log file whose names
+// match the rotation naming scheme are returned. A compressed file is skipped
+// while its original file still exists, i.e. being compressed.
+func (l *fileLogger) rotatedFiles() ([]rotatedFile, error) {
+	dir := filepath.Dir(l.filename)
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(fis))
+	for _, fi := range fis {
+		names[fi.Name()] = true
+	}
+
+	files := make([]rotatedFile, 0, len(fis))
+	for _, fi := range fis {
+		name := fi.Name()
+		if !fi.Mode().IsRegular() {
+			continue
+		} else if strings.HasSuffix(name, compressSuffix) && names[strings.TrimSuffix(name, compressSuffix)] {
+			continue
+		}
+
+		date, seq, ok := l.naming.parse(name)
+		if !ok || name == filepath.Base(l.filename) {
+			continue
+		}
+		files = append(files, rotatedFile{
+			path: filepath.Join(dir, name),
+			date: date,
+			seq:  seq,
+			size: fi.Size(),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].date.Equal(files[j].date) {
+			return files[i].seq > files[j].seq
+		}
+		return files[i].date.After(files[j].date)
 	})
+	return files, nil
 }
 
-func (l *fileLogger) initRotation() error {
-	// Gather basic file info for rotation.
+// deleteOutdatedFiles deletes rotated files, either compressed or not, whose
+// dates in names are older than MaxDays. Neither the file being written nor
+// files being compressed are deleted.
+func (l *fileLogger) deleteOutdatedFiles() error {
+	if l.rotationConfig.MaxDays <= 0 {
+		return nil
+	}
+
+	files, err := l.rotatedFiles()
+	if err != nil {
+		return fmt.Errorf("list rotated files: %v", err)
+	}
+
+	now := time.Now().In(l.naming.location)
+	cutoff := time.Date(now.Year(), now.Month(), now.Day()-int(l.rotationConfig.MaxDays), 0, 0, 0, 0, now.Location())
+	for _, f := range files {
+		if !f.date.Before(cutoff) || l.isCompressing(f.path) {
+			continue
+		}
+		if err = os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteExcessFiles deletes the oldest rotated files beyond MaxBackups or
+// MaxTotalSize, files being compressed are never deleted.
+func (l *fileLogger) deleteExcessFiles() error {
+	maxBackups, maxTotalSize := l.rotationConfig.MaxBackups, l.rotationConfig.MaxTotalSize
+	if maxBackups <= 0 && maxTotalSize <= 0 {
+		return nil
+	}
+
+	files, err := l.rotatedFiles()
+	if err != nil {
+		return fmt.Errorf("list rotated files: %v", err)
+	}
+
+	total := l.currentSize
+	for i, f := range files {
+		total += f.size
+		if (maxBackups > 0 && i >= maxBackups) ||
+			(maxTotalSize > 0 && total > maxTotalSize) {
+			// Files being compressed are counted but left to the next
+			// deletion after the compression finishes.
+			if l.isCompressing(f.path) {
+				continue
+			}
+			if err = os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// initCounters initializes the size and lines of the file being written for
+// rotation, it returns the file info.
+func (l *fileLogger) initCounters() (os.FileInfo, error) {
 	fi, err := l.file.Stat()
 	if err != nil {
-		return fmt.Errorf("stat: %v", err)
+		return nil, fmt.Errorf("stat: %v", err)
 	}
 
 	l.currentSize = fi.Size()
+	l.currentLines = 0
 
-	// If there is any content in the file, count the number of lines.
+	// If there is any content in the file, count the number of lines the same
+	// way as write does, i.e. the number of newlines.
 	if l.rotationConfig.MaxLines > 0 && l.currentSize > 0 {
 		data, err := ioutil.ReadFile(l.filename)
 		if err != nil {
-			return fmt.Errorf("read file %q: %v", l.filename, err)
+			return nil, fmt.Errorf("read file %q: %v", l.filename, err)
 		}
 
-		l.currentLines = int64(bytes.Count(data, newLineBytes)) + 1
+		l.currentLines = int64(bytes.Count(data, newLineBytes))
 	}
+	return fi, nil
+}
 
-	if l.rotationConfig.Daily {
-		now := time.Now()
-		l.openDay = now.Day()
-
-		lastWriteTime := fi.ModTime()
-		if lastWriteTime.Year() != now.Year() ||
-			lastWriteTime.Month() != now.Month() ||
-			lastWriteTime.Day() != now.Day() {
+func (l *fileLogger) initRotation() error {
+	// Gather basic file info for rotation.
+	fi, err := l.initCounters()
+	if err != nil {
+		return err
+	}
 
-			if err = l.file.Close(); err != nil {
-				return fmt.Errorf("close current file: %v", err)
-			}
-			if err = os.Rename(l.filename, rotateFilename(l.filename, lastWriteTime.Format(simpleDateFormat))); err != nil {
-				return fmt.Errorf("rename rotate file: %v", err)
-			}
+	if interval := l.interval(); interval > 0 {
+		l.openPeriod = alignTime(time.Now(), interval, l.naming.location)
 
-			if err = l.initFile(); err != nil {
-				return fmt.Errorf("init file: %v", err)
+		// Rotate the file if it was last written in a previous period.
+		lastPeriod := alignTime(fi.ModTime(), interval, l.naming.location)
+		if l.currentSize > 0 && !lastPeriod.Equal(l.openPeriod) {
+			if err = l.rotate(lastPeriod); err != nil {
+				return err
 			}
 		}
 	}
 
-	if l.rotationConfig.MaxDays > 0 {
-		if err = l.deleteOutdatedFiles(); err != nil {
-			return fmt.Errorf("delete outdated files: %v", err)
-		}
+	return l.deleteRotatedFiles()
+}
+
+// deleteRotatedFiles deletes rotated files that are outdated or in excess.
+func (l *fileLogger) deleteRotatedFiles() error {
+	if err := l.deleteOutdatedFiles(); err != nil {
+		return fmt.Errorf("delete outdated files: %v", err)
+	}
+	if err := l.deleteExcessFiles(); err != nil {
+		return fmt.Errorf("delete excess files: %v", err)
 	}
 	return nil
 }
 
-func (l *fileLogger) write(m Messager) (int, error) {
-	l.Logger.Print(m.String())
+// rotateEpoch is the wall clock time that intervals are aligned to, it is a
+// Monday so weekly intervals start on Mondays.
+var rotateEpoch = time.Date(1970, 1, 5, 0, 0, 0, 0, time.UTC)
+
+// alignTime returns the start of the interval that the time belongs to, the
+// boundaries of intervals are aligned to the wall clock in given time zone.
+func alignTime(t time.Time, interval time.Duration, loc *time.Location) time.Time {
+	// Compute with the wall clock time as if it was in UTC, which has neither
+	// gaps nor overlaps of daylight saving time.
+	t = t.In(loc)
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	offset := wall.Sub(rotateEpoch) % interval
+	if offset < 0 {
+		offset += interval
+	}
+	start := wall.Add(-offset)
+	return time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), start.Second(), 0, loc)
+}
 
-	bytesWrote := len(m.String())
-	if !l.standalone {
-		bytesWrote += logPrefixLength
+// interval returns the interval of time-based rotation, or zero if disabled.
+func (l *fileLogger) interval() time.Duration {
+	if l.rotationConfig.Interval > 0 {
+		return l.rotationConfig.Interval
+	} else if l.rotationConfig.Daily {
+		return 24 * time.Hour
 	}
-	if l.rotationConfig.Rotate {
-		l.currentSize += int64(bytesWrote)
-		l.currentLines += int64(strings.Count(m.String(), "\n")) + 1
+	return 0
+}
 
-		var (
-			needsRotate = false
-			rotateDate  time.Time
-		)
+// rotate renames the file being written to the next rotated file name of given
+// time and opens a new file. The counters are reset even if it fails, so the
+// rotation is not retried for every message.
+func (l *fileLogger) rotate(t time.Time) error {
+	l.currentSize = 0
+	l.currentLines = 0
 
-		now := time.Now()
-		if l.rotationConfig.Daily && now.Day() != l.openDay {
-			needsRotate = true
-			rotateDate = now.Add(-24 * time.Hour)
+	rotated, err := l.naming.next(filepath.Dir(l.filename), t)
+	if err != nil {
+		return fmt.Errorf("rotate file %q: %v", l.filename, err)
+	}
 
-		} else if (l.rotationConfig.MaxSize > 0 && l.currentSize >= l.rotationConfig.MaxSize) ||
-			(l.rotationConfig.MaxLines > 0 && l.currentLines >= l.rotationConfig.MaxLines) {
-			needsRotate = true
-			rotateDate = now
+	_ = l.file.Close()
+	if err = os.Rename(l.filename, rotated); err != nil {
+		// Keep writing to the same file.
+		if err := l.initFile(); err != nil {
+			return fmt.Errorf("init file %q: %v", l.filename, err)
 		}
+		return fmt.Errorf("rename rotated file %q: %v", l.filename, err)
+	}
+	l.compress(rotated)
 
-		if needsRotate {
-			_ = l.file.Close()
-			if err := os.Rename(l.filename, rotateFilename(l.filename, rotateDate.Format(simpleDateFormat))); err != nil {
-				return bytesWrote, fmt.Errorf("rename rotated file %q: %v", l.filename, err)
-			}
+	if err = l.initFile(); err != nil {
+		return fmt.Errorf("init file %q: %v", l.filename, err)
+	}
+	return nil
+}
+
+// write writes the message to the file and does rotation if needed. It returns
+// the length of the message string.
+func (l *fileLogger) write(m Messager) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return 0, fmt.Errorf("file %q is closed", l.filename)
+	}
 
-			if err := l.initFile(); err != nil {
-				return bytesWrote, fmt.Errorf("init file %q: %v", l.filename, err)
+	p, err := l.formatter.Format(m)
+	if err != nil {
+		return 0, fmt.Errorf("format: %v", err)
+	}
+
+	// Rotate before writing the message when a new period starts, so the
+	// message goes to the file of its own period.
+	var rotated bool
+	var rotateErr error
+	if interval := l.interval(); l.rotationConfig.Rotate && interval > 0 {
+		if period := alignTime(time.Now(), interval, l.naming.location); !period.Equal(l.openPeriod) {
+			openPeriod := l.openPeriod
+			l.openPeriod = period
+			if l.currentSize > 0 {
+				rotateErr = l.rotate(openPeriod)
+				if l.file == nil {
+					return 0, rotateErr
+				}
+				rotated = rotateErr == nil
 			}
+		}
+	}
 
-			l.openDay = now.Day()
-			l.currentSize = 0
-			l.currentLines = 0
+	if _, err = l.file.Write(p); err != nil {
+		return 0, fmt.Errorf("write file %q: %v", l.filename, err)
+	}
 
-			if err := l.deleteOutdatedFiles(); err != nil {
-				return bytesWrote, fmt.Errorf("delete outdated file: %v", err)
+	bytesWrote := len(m.String())
+	if l.rotationConfig.Rotate {
+		l.currentSize += int64(len(p))
+		l.currentLines += int64(bytes.Count(p, newLineBytes))
+
+		if rotateErr == nil &&
+			((l.rotationConfig.MaxSize > 0 && l.currentSize >= l.rotationConfig.MaxSize) ||
+				(l.rotationConfig.MaxLines > 0 && l.currentLines >= l.rotationConfig.MaxLines)) {
+			if rotateErr = l.rotate(time.Now()); rotateErr == nil {
+				rotated = true
 			}
 		}
 	}
+	if rotateErr != nil {
+		return bytesWrote, rotateErr
+	}
+
+	if rotated {
+		if err = l.deleteRotatedFiles(); err != nil {
+			return bytesWrote, err
+		}
+	}
 	return bytesWrote, nil
 }
 
@@ -213,20 +794,85 @@ func (l *fileLogger) Write(m Messager) error {
 	return err
 }
 
-func (l *fileLogger) init() error {
-	_ = os.MkdirAll(filepath.Dir(l.filename), os.ModePerm)
+// Flush implements method of Flusher interface, it reports errors of
+// compressing rotated files in the background.
+func (l *fileLogger) Flush() error {
+	return l.compressError()
+}
+
+// Close implements method of io.Closer interface, it waits for rotated files
+// being compressed.
+func (l *fileLogger) Close() error {
+	l.mu.Lock()
+	var err error
+	if l.file != nil {
+		err = l.file.Close()
+		l.file = nil
+	}
+	l.mu.Unlock()
+
+	l.compressing.Wait()
+	if err != nil {
+		return err
+	}
+	return l.compressError()
+}
+
+// Reopen implements method of Reopener interface, it closes and reopens the
+// file by its name and reinitializes the rotation counters from the reopened
+// file, so writing continues in a new file after the file is moved by external
+// rotation tools.
+func (l *fileLogger) Reopen() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("file %q is closed", l.filename)
+	}
+
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("close file %q: %v", l.filename, err)
+	}
 	if err := l.initFile(); err != nil {
 		return fmt.Errorf("init file %q: %v", l.filename, err)
 	}
+	if _, err := l.initCounters(); err != nil {
+		return fmt.Errorf("init counters: %v", err)
+	}
+	return nil
+}
 
+func (l *fileLogger) init() (err error) {
 	if l.rotationConfig.Rotate {
-		if err := l.initRotation(); err != nil {
+		if l.rotationConfig.Interval < 0 || l.rotationConfig.Interval%time.Second != 0 {
+			return fmt.Errorf("init rotation: interval must be a non-negative multiple of a second, but got %v", l.rotationConfig.Interval)
+		}
+
+		l.naming, err = newRotateNaming(l.filename, l.rotationConfig)
+		if err != nil {
+			return fmt.Errorf("init rotation: %v", err)
+		}
+	}
+
+	_ = os.MkdirAll(filepath.Dir(l.filename), os.ModePerm)
+	if err = l.initFile(); err != nil {
+		return fmt.Errorf("init file %q: %v", l.filename, err)
+	}
+
+	if l.rotationConfig.Rotate {
+		if err = l.initRotation(); err != nil {
 			return fmt.Errorf("init rotation: %v", err)
 		}
 	}
 	return nil
 }
 
+// ModeFile is the mode name of the file logger.
+const ModeFile = "file"
+
+// Mode returns the mode name of the logger.
+func (*fileLogger) Mode() string { return ModeFile }
+
 // DefaultFileName is the default name for the file logger.
 const DefaultFileName = "file"
 
@@ -242,14 +888,40 @@ func NewFileWithName(name string, vs ...interface{}) error {
 	return New(name, FileIniter(), vs...)
 }
 
+// FileOptioner is a typed option accepted by NewFileWithOptions, it is
+// implemented by Option and FileOption only.
+type FileOptioner interface {
+	fileOption()
+}
+
+func (Option) fileOption()     {}
+func (FileOption) fileOption() {}
+
+// NewFileWithOptions initializes and appends a new file logger with given
+// name to the managed list. Unlike NewFileWithName, only typed options are
+// accepted so that mistakes are caught at compile time.
+func NewFileWithOptions(name string, opts ...FileOptioner) error {
+	vs := make([]interface{}, len(opts))
+	for i := range opts {
+		vs[i] = opts[i]
+	}
+	return New(name, FileIniter(), vs...)
+}
+
 // FileIniter returns the initer for the file logger.
 func FileIniter() Initer {
 	return func(name string, vs ...interface{}) (Logger, error) {
 		var cfg *FileConfig
+		var opts []FileOption
 		for i := range vs {
 			switch v := vs[i].(type) {
 			case FileConfig:
 				cfg = &v
+			case FileOption:
+				opts = append(opts, v)
+			case nil:
+			default:
+				return nil, fmt.Errorf("unrecognized option of type '%T'", v)
 			}
 		}
 
@@ -258,6 +930,9 @@ func FileIniter() Initer {
 				Filename: "clog.log",
 			}
 		}
+		for _, opt := range opts {
+			opt(cfg)
+		}
 
 		l := &fileLogger{
 			noopLogger: &noopLogger{
@@ -265,8 +940,12 @@ func FileIniter() Initer {
 				level: cfg.Level,
 			},
 			filename:       cfg.Filename,
+			formatter:      cfg.Formatter,
 			rotationConfig: cfg.FileRotationConfig,
 		}
+		if l.formatter == nil {
+			l.formatter = TextFormatter{}
+		}
 
 		if err := l.init(); err != nil {
 			return nil, err
@@ -282,11 +961,13 @@ type fileWriter struct {
 	*fileLogger
 }
 
-// NewFileWriter returns an io.Writer for synchronized file logger.
+// NewFileWriter returns an io.Writer for synchronized file logger. The writer
+// also implements Reopener.
 func NewFileWriter(filename string, cfg FileRotationConfig) (io.Writer, error) {
 	f := &fileLogger{
 		standalone:     true,
 		filename:       filename,
+		formatter:      rawFormatter{},
 		rotationConfig: cfg,
 	}
 	if err := f.init(); err != nil {
@@ -299,6 +980,8 @@ func NewFileWriter(filename string, cfg FileRotationConfig) (io.Writer, error) {
 // Write implements method of io.Writer interface.
 func (w *fileWriter) Write(p []byte) (int, error) {
 	return w.write(&message{
+		text: string(p),
 		body: string(p),
+		time: time.Now(),
 	})
 }
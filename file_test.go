@@ -1,12 +1,17 @@
 package clog
 
 import (
+	"compress/gzip"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -17,6 +22,8 @@ func Test_fileLogger(t *testing.T) {
 	}
 
 	testName := "Test_fileLogger"
+	// Loggers without a file name write to "clog.log" in the working directory.
+	defer os.Remove("clog.log")
 	defer Remove(DefaultFileName)
 	defer Remove(testName)
 
@@ -47,6 +54,17 @@ func Test_fileLogger(t *testing.T) {
 			wantErr: nil,
 		},
 
+		{
+			name:    "typed options",
+			mode:    testName,
+			config:  WithFilename(filepath.Join(os.TempDir(), "Test_fileLogger_options")),
+			wantErr: nil,
+		},
+		{
+			name:    "config of other mode",
+			config:  ConsoleConfig{},
+			wantErr: errors.New("initialize logger: unrecognized option of type 'clog.ConsoleConfig'"),
+		},
 		{
 			name: "invalid filename",
 			config: FileConfig{
@@ -62,24 +80,787 @@ func Test_fileLogger(t *testing.T) {
 	}
 
 	assert.Equal(t, 2, mgr.len())
-	assert.Equal(t, DefaultFileName, mgr.loggers[0].Name())
-	assert.Equal(t, LevelInfo, mgr.loggers[0].Level())
-	assert.Equal(t, testName, mgr.loggers[1].Name())
-	assert.Equal(t, LevelTrace, mgr.loggers[1].Level())
+	assert.Equal(t, DefaultFileName, mgr.loggers()[0].Name())
+	assert.Equal(t, LevelInfo, mgr.loggers()[0].Level())
+	assert.Equal(t, testName, mgr.loggers()[1].Name())
+	assert.Equal(t, LevelTrace, mgr.loggers()[1].Level())
 }
 
-func Test_rotateFilename(t *testing.T) {
+func Test_fileLogger_formatter(t *testing.T) {
 	_ = os.MkdirAll("test", os.ModePerm)
 	defer os.RemoveAll("test")
 
-	filename := rotateFilename("test/Test_rotateFilename.log", "2017-03-05")
-	assert.Equal(t, "test/Test_rotateFilename.log.2017-03-05", filename)
-	assert.Nil(t, ioutil.WriteFile(filename, []byte(""), os.ModePerm))
+	l, err := FileIniter()("Test_fileLogger_formatter", FileConfig{
+		Filename:  "test/Test_fileLogger_formatter.log",
+		Formatter: LogfmtFormatter{},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, l.Write(newMessage(LevelInfo, 0, "user login", Fields{"user_id": 42})))
+	assert.Nil(t, l.(*fileLogger).file.Close())
+
+	data, err := ioutil.ReadFile("test/Test_fileLogger_formatter.log")
+	assert.Nil(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^time=\S+ level=info msg="user login" user_id=42\n$`), string(data))
+}
+
+func Test_fileLogger_Close(t *testing.T) {
+	_ = os.MkdirAll("test", os.ModePerm)
+	defer os.RemoveAll("test")
+
+	m := NewManager()
+	defer m.Stop()
+
+	filename := filepath.Join("test", "Test_fileLogger_Close.log")
+	assert.Nil(t, m.New("file", FileIniter(), 10, FileConfig{Filename: filename}))
+	l, _ := m.lookup("file")
+	fl := l.Logger.(*fileLogger)
+
+	m.Info("before removal")
+	m.Remove("file")
+	assert.Nil(t, fl.file)
+	assert.Equal(t, fmt.Errorf("file %q is closed", filename), fl.Write(newMessage(LevelInfo, 0, "after removal")))
+
+	data, err := ioutil.ReadFile(filename)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "before removal")
+}
+
+func Test_newRotateNaming(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  FileRotationConfig
+		wantErr error
+	}{
+		{
+			name: "default",
+		},
+		{
+			name:   "pattern",
+			config: FileRotationConfig{FilenamePattern: "app-{date}-{seq}.log"},
+		},
+		{
+			name:    "pattern without time",
+			config:  FileRotationConfig{FilenamePattern: "app-{seq}.log"},
+			wantErr: errors.New(`filename pattern "app-{seq}.log" must contain {date} or {timestamp}`),
+		},
+		{
+			name:    "pattern with path separator",
+			config:  FileRotationConfig{FilenamePattern: "old/app-{date}.log"},
+			wantErr: errors.New(`filename pattern "old/app-{date}.log" must not contain path separators`),
+		},
+		{
+			name: "pattern without sequence by size",
+			config: FileRotationConfig{
+				MaxSize:         1024,
+				FilenamePattern: "app-{date}.log",
+			},
+			wantErr: errors.New(`filename pattern "app-{date}.log" must contain {seq} when rotating by size or lines`),
+		},
+		{
+			name: "pattern without sequence by lines",
+			config: FileRotationConfig{
+				MaxLines:        100,
+				FilenamePattern: "app.{timestamp}.log",
+			},
+			wantErr: errors.New(`filename pattern "app.{timestamp}.log" must contain {seq} when rotating by size or lines`),
+		},
+		{
+			name: "pattern without sequence by interval",
+			config: FileRotationConfig{
+				Interval:        time.Hour,
+				FilenamePattern: "app.{timestamp}.log",
+			},
+		},
+		{
+			name:    "time format with path separator",
+			config:  FileRotationConfig{DateFormat: "2006/01/02"},
+			wantErr: errors.New(`time format "2006/01/02" must not contain path separators`),
+		},
+		{
+			name:    "date format without day",
+			config:  FileRotationConfig{DateFormat: "2006-01"},
+			wantErr: errors.New(`date format "2006-01" must contain the year, month and day`),
+		},
+		{
+			name: "timestamp format without date",
+			config: FileRotationConfig{
+				FilenamePattern: "app.{timestamp}.log",
+				TimestampFormat: "15h04",
+			},
+			wantErr: errors.New(`timestamp format "15h04" must contain the year, month and day without {date}`),
+		},
+		{
+			name: "timestamp format without date but with date",
+			config: FileRotationConfig{
+				FilenamePattern: "app.{date}.{timestamp}.log",
+				TimestampFormat: "15h04",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newRotateNaming("app.log", tt.config)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func Test_rotateNaming_next(t *testing.T) {
+	date := time.Date(2017, 3, 5, 13, 14, 15, 0, time.Local)
+
+	t.Run("default", func(t *testing.T) {
+		_ = os.MkdirAll("test", os.ModePerm)
+		defer os.RemoveAll("test")
+
+		n, err := newRotateNaming(filepath.Join("test", "Test_rotateFilename.log"), FileRotationConfig{})
+		assert.Nil(t, err)
+
+		filename, err := n.next("test", date)
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join("test", "Test_rotateFilename.log.2017-03-05"), filename)
+		assert.Nil(t, ioutil.WriteFile(filename, []byte(""), os.ModePerm))
+
+		filename, err = n.next("test", date)
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join("test", "Test_rotateFilename.log.2017-03-05.001"), filename)
+		assert.Nil(t, ioutil.WriteFile(filename, []byte(""), os.ModePerm))
+
+		filename, err = n.next("test", date)
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join("test", "Test_rotateFilename.log.2017-03-05.002"), filename)
+
+		// Compressed files are taken into account.
+		assert.Nil(t, ioutil.WriteFile(filename+".gz", []byte(""), os.ModePerm))
+		filename, err = n.next("test", date)
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join("test", "Test_rotateFilename.log.2017-03-05.003"), filename)
+	})
+
+	t.Run("pattern", func(t *testing.T) {
+		_ = os.MkdirAll("test", os.ModePerm)
+		defer os.RemoveAll("test")
 
-	filename = rotateFilename("test/Test_rotateFilename.log", "2017-03-05")
-	assert.Equal(t, "test/Test_rotateFilename.log.2017-03-05.001", filename)
-	assert.Nil(t, ioutil.WriteFile(filename, []byte(""), os.ModePerm))
+		n, err := newRotateNaming(filepath.Join("test", "app.log"), FileRotationConfig{
+			FilenamePattern: "app-{date}-{seq}.log",
+			DateFormat:      "20060102",
+		})
+		assert.Nil(t, err)
+
+		filename, err := n.next("test", date)
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join("test", "app-20170305-001.log"), filename)
+		assert.Nil(t, ioutil.WriteFile(filename, []byte(""), os.ModePerm))
+
+		filename, err = n.next("test", date)
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join("test", "app-20170305-002.log"), filename)
+	})
+
+	t.Run("pattern without sequence", func(t *testing.T) {
+		_ = os.MkdirAll("test", os.ModePerm)
+		defer os.RemoveAll("test")
+
+		n, err := newRotateNaming(filepath.Join("test", "app.log"), FileRotationConfig{FilenamePattern: "app.{timestamp}.log"})
+		assert.Nil(t, err)
+
+		filename, err := n.next("test", date)
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join("test", "app.20170305131415.log"), filename)
+		assert.Nil(t, ioutil.WriteFile(filename, []byte(""), os.ModePerm))
+
+		_, err = n.next("test", date)
+		assert.Equal(t, fmt.Errorf("rotated file %q already exists", filepath.Join("test", "app.20170305131415.log")), err)
+	})
+
+	t.Run("sequence exhausted", func(t *testing.T) {
+		_ = os.MkdirAll("test", os.ModePerm)
+		defer os.RemoveAll("test")
+
+		n, err := newRotateNaming(filepath.Join("test", "app.log"), FileRotationConfig{})
+		assert.Nil(t, err)
+		for seq := 0; seq <= maxRotateSeq; seq++ {
+			assert.Nil(t, ioutil.WriteFile(filepath.Join("test", n.format(date, seq)), nil, 0644))
+		}
+
+		_, err = n.next("test", date)
+		assert.Equal(t, errors.New(`too many rotated files of "app.log.2017-03-05", already reached 999`), err)
+	})
+}
+
+func Test_rotateNaming_parse(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   FileRotationConfig
+		wantTime string
+		wantSeq  int
+		wantOK   bool
+	}{
+		{name: "app.log.2017-03-05", wantTime: "2017-03-05 00:00:00", wantOK: true},
+		{name: "app.log.2017-03-05.gz", wantTime: "2017-03-05 00:00:00", wantOK: true},
+		{name: "app.log.2017-03-05.012", wantTime: "2017-03-05 00:00:00", wantSeq: 12, wantOK: true},
+		{name: "app.log.2017-03-05.012.gz", wantTime: "2017-03-05 00:00:00", wantSeq: 12, wantOK: true},
+		{name: "app.log"},
+		{name: "app.log.bak"},
+		{name: "app.log.2017-3-5"},
+		{name: "app.log.2017-02-30"},
+		{name: "app.log.2017-03-05.bak"},
+		{name: "app.log.2017-03-05.000"},
+		{name: "app.log.2017-03-05.12"},
+		{name: "app.log.2017-03-05.+12"},
+		{name: "other.log.2017-03-05"},
+		{name: "app.logs.2017-03-05"},
+
+		{
+			name:     "app-2017-03-05-003.log",
+			config:   FileRotationConfig{FilenamePattern: "app-{date}-{seq}.log"},
+			wantTime: "2017-03-05 00:00:00",
+			wantSeq:  3,
+			wantOK:   true,
+		},
+		{
+			name:     "app-2017-03-05-003.log.gz",
+			config:   FileRotationConfig{FilenamePattern: "app-{date}-{seq}.log"},
+			wantTime: "2017-03-05 00:00:00",
+			wantSeq:  3,
+			wantOK:   true,
+		},
+		{
+			name:   "app-2017-03-05.log",
+			config: FileRotationConfig{FilenamePattern: "app-{date}-{seq}.log"},
+		},
+		{
+			name:   "app-2017-03-05-003.txt",
+			config: FileRotationConfig{FilenamePattern: "app-{date}-{seq}.log"},
+		},
+		{
+			name:     "app.20170305131415.log",
+			config:   FileRotationConfig{FilenamePattern: "app.{timestamp}.log"},
+			wantTime: "2017-03-05 13:14:15",
+			wantOK:   true,
+		},
+		{
+			name: "app.05-03-2017.13h14.log",
+			config: FileRotationConfig{
+				FilenamePattern: "app.{date}.{timestamp}.log",
+				DateFormat:      "02-01-2006",
+				TimestampFormat: "15h04",
+			},
+			wantTime: "2017-03-05 13:14:00",
+			wantOK:   true,
+		},
+		{
+			name:   "app.2017-03-05.log",
+			config: FileRotationConfig{FilenamePattern: "app.{timestamp}.log"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := newRotateNaming("app.log", tt.config)
+			assert.Nil(t, err)
+
+			tm, seq, ok := n.parse(tt.name)
+			assert.Equal(t, tt.wantOK, ok)
+			if ok {
+				assert.Equal(t, tt.wantTime, tm.Format("2006-01-02 15:04:05"))
+			}
+			assert.Equal(t, tt.wantSeq, seq)
+		})
+	}
+}
 
-	filename = rotateFilename("test/Test_rotateFilename.log", "2017-03-05")
-	assert.Equal(t, "test/Test_rotateFilename.log.2017-03-05.002", filename)
+func Test_alignTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("Skipping testing without time zone database")
+	}
+
+	tests := []struct {
+		name     string
+		t        time.Time
+		interval time.Duration
+		loc      *time.Location
+		want     time.Time
+	}{
+		{
+			name:     "hourly",
+			t:        time.Date(2017, 3, 5, 13, 14, 15, 0, time.UTC),
+			interval: time.Hour,
+			loc:      time.UTC,
+			want:     time.Date(2017, 3, 5, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "every 15 minutes",
+			t:        time.Date(2017, 3, 5, 13, 44, 15, 0, time.UTC),
+			interval: 15 * time.Minute,
+			loc:      time.UTC,
+			want:     time.Date(2017, 3, 5, 13, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "daily",
+			t:        time.Date(2017, 3, 5, 13, 14, 15, 0, time.UTC),
+			interval: 24 * time.Hour,
+			loc:      time.UTC,
+			want:     time.Date(2017, 3, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "weekly starts on Monday",
+			t:        time.Date(2017, 3, 5, 13, 14, 15, 0, time.UTC), // Sunday
+			interval: 7 * 24 * time.Hour,
+			loc:      time.UTC,
+			want:     time.Date(2017, 2, 27, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "daily in another time zone",
+			t:        time.Date(2017, 3, 5, 3, 0, 0, 0, time.UTC),
+			interval: 24 * time.Hour,
+			loc:      newYork,
+			want:     time.Date(2017, 3, 4, 0, 0, 0, 0, newYork),
+		},
+		{
+			name:     "hourly after daylight saving time starts",
+			t:        time.Date(2017, 3, 12, 3, 30, 0, 0, newYork),
+			interval: time.Hour,
+			loc:      newYork,
+			want:     time.Date(2017, 3, 12, 3, 0, 0, 0, newYork),
+		},
+		{
+			name:     "before epoch",
+			t:        time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC),
+			interval: time.Hour,
+			loc:      time.UTC,
+			want:     time.Date(1969, 12, 31, 23, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := alignTime(tt.t, tt.interval, tt.loc)
+			assert.True(t, tt.want.Equal(got), "want %v but got %v", tt.want, got)
+		})
+	}
+
+	// Same day of different months are in different periods.
+	assert.False(t, alignTime(time.Date(2017, 1, 5, 0, 0, 0, 0, time.UTC), 24*time.Hour, time.UTC).Equal(
+		alignTime(time.Date(2017, 2, 5, 0, 0, 0, 0, time.UTC), 24*time.Hour, time.UTC)))
+}
+
+func Test_fileLogger_Interval(t *testing.T) {
+	_ = os.MkdirAll("test", os.ModePerm)
+	defer os.RemoveAll("test")
+
+	t.Run("invalid interval", func(t *testing.T) {
+		_, err := FileIniter()("Test_fileLogger_Interval", FileConfig{
+			Filename: filepath.Join("test", "invalid.log"),
+			FileRotationConfig: FileRotationConfig{
+				Rotate:   true,
+				Interval: 1500 * time.Millisecond,
+			},
+		})
+		assert.Equal(t, errors.New("init rotation: interval must be a non-negative multiple of a second, but got 1.5s"), err)
+	})
+
+	l, err := FileIniter()("Test_fileLogger_Interval", FileConfig{
+		Filename: filepath.Join("test", "app.log"),
+		FileRotationConfig: FileRotationConfig{
+			Rotate:          true,
+			Interval:        time.Hour,
+			Location:        time.UTC,
+			FilenamePattern: "app.{timestamp}.log",
+			TimestampFormat: "2006-01-02T15",
+		},
+	})
+	assert.Nil(t, err)
+	fl := l.(*fileLogger)
+	defer fl.Close()
+
+	// Nothing to rotate for an empty file.
+	fl.openPeriod = fl.openPeriod.Add(-time.Hour)
+	assert.Nil(t, l.Write(newMessage(LevelInfo, 0, "first")))
+	assert.Equal(t, []string{"app.log"}, listFiles(t, "test"))
+
+	// Pretend the message was written in the previous hour.
+	period := fl.openPeriod
+	fl.openPeriod = period.Add(-time.Hour)
+	assert.Nil(t, l.Write(newMessage(LevelInfo, 0, "second")))
+	assert.True(t, period.Equal(fl.openPeriod))
+
+	rotated := "app." + period.Add(-time.Hour).Format("2006-01-02T15") + ".log"
+	assert.Equal(t, []string{rotated, "app.log"}, listFiles(t, "test"))
+
+	data, err := ioutil.ReadFile(filepath.Join("test", rotated))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "first")
+	assert.NotContains(t, string(data), "second")
+
+	data, err = ioutil.ReadFile(filepath.Join("test", "app.log"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "second")
+}
+
+func Test_fileLogger_FilenamePattern(t *testing.T) {
+	_ = os.MkdirAll("test", os.ModePerm)
+	defer os.RemoveAll("test")
+
+	l, err := FileIniter()("Test_fileLogger_FilenamePattern", FileConfig{
+		Filename: filepath.Join("test", "app.log"),
+		FileRotationConfig: FileRotationConfig{
+			Rotate:          true,
+			MaxLines:        1,
+			FilenamePattern: "app-{date}-{seq}.log",
+		},
+	})
+	assert.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, l.Write(newMessage(LevelInfo, 0, "message")))
+	}
+	assert.Nil(t, l.(*fileLogger).Close())
+
+	date := time.Now().Format(simpleDateFormat)
+	assert.Equal(t,
+		[]string{"app-" + date + "-001.log", "app-" + date + "-002.log", "app-" + date + "-003.log", "app.log"},
+		listFiles(t, "test"),
+	)
+}
+
+// setupRotatedFiles creates the log file "app.log" with its rotated files and
+// unrelated files in the "test" directory.
+func setupRotatedFiles(t *testing.T, files map[string]int) {
+	_ = os.MkdirAll(filepath.Join("test", "sub"), os.ModePerm)
+	for _, name := range []string{"app.log", "app.log.bak", "other.log", filepath.Join("sub", "app.log.2000-01-01")} {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join("test", name), nil, 0644))
+	}
+	for name, size := range files {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join("test", name), make([]byte, size), 0644))
+	}
+
+	// Modification times must not matter.
+	now := time.Now()
+	_ = filepath.Walk("test", func(path string, _ os.FileInfo, _ error) error {
+		return os.Chtimes(path, now, now)
+	})
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	fis, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	var names []string
+	for _, fi := range fis {
+		if !fi.IsDir() {
+			names = append(names, fi.Name())
+		}
+	}
+	return names
+}
+
+func Test_fileLogger_deleteOutdatedFiles(t *testing.T) {
+	_ = os.MkdirAll("test", os.ModePerm)
+	defer os.RemoveAll("test")
+
+	now := time.Now()
+	date := func(days int) string {
+		return now.AddDate(0, 0, -days).Format(simpleDateFormat)
+	}
+	setupRotatedFiles(t, map[string]int{
+		"app.log." + date(1):             10,
+		"app.log." + date(2) + ".001.gz": 10,
+		"app.log." + date(3):             10,
+		"app.log." + date(3) + ".001":    10,
+	})
+
+	l := &fileLogger{
+		filename:       filepath.Join("test", "app.log"),
+		rotationConfig: FileRotationConfig{MaxDays: 2},
+	}
+	l.naming, _ = newRotateNaming(l.filename, l.rotationConfig)
+	assert.Nil(t, l.deleteOutdatedFiles())
+	assert.Equal(t,
+		[]string{"app.log", "app.log." + date(2) + ".001.gz", "app.log." + date(1), "app.log.bak", "other.log"},
+		listFiles(t, "test"),
+	)
+	assert.Equal(t, []string{"app.log.2000-01-01"}, listFiles(t, filepath.Join("test", "sub")))
+
+	// The date is taken from {date} when {timestamp} has no date.
+	current := "app." + now.Format("2006-01-02.15h04") + ".log"
+	outdated := "app." + now.AddDate(0, 0, -3).Format("2006-01-02.15h04") + ".log"
+	for _, name := range []string{current, outdated} {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join("test", name), nil, 0644))
+	}
+	l.rotationConfig.FilenamePattern = "app.{date}.{timestamp}.log"
+	l.rotationConfig.TimestampFormat = "15h04"
+	l.naming, _ = newRotateNaming(l.filename, l.rotationConfig)
+	assert.Nil(t, l.deleteOutdatedFiles())
+	assert.True(t, isExist(filepath.Join("test", current)))
+	assert.False(t, isExist(filepath.Join("test", outdated)))
+}
+
+func Test_fileLogger_deleteExcessFiles(t *testing.T) {
+	files := map[string]int{
+		"app.log.2017-03-04":        10,
+		"app.log.2017-03-05":        10,
+		"app.log.2017-03-05.001.gz": 10,
+		"app.log.2017-03-05.002":    10,
+	}
+
+	tests := []struct {
+		name     string
+		config   FileRotationConfig
+		current  int64
+		wantLeft []string
+	}{
+		{
+			name:     "no limit",
+			wantLeft: []string{"app.log", "app.log.2017-03-04", "app.log.2017-03-05", "app.log.2017-03-05.001.gz", "app.log.2017-03-05.002", "app.log.bak", "other.log"},
+		},
+		{
+			name:     "max backups",
+			config:   FileRotationConfig{MaxBackups: 2},
+			wantLeft: []string{"app.log", "app.log.2017-03-05.001.gz", "app.log.2017-03-05.002", "app.log.bak", "other.log"},
+		},
+		{
+			name:     "max total size",
+			config:   FileRotationConfig{MaxTotalSize: 35},
+			current:  5,
+			wantLeft: []string{"app.log", "app.log.2017-03-05", "app.log.2017-03-05.001.gz", "app.log.2017-03-05.002", "app.log.bak", "other.log"},
+		},
+		{
+			name:     "both",
+			config:   FileRotationConfig{MaxBackups: 2, MaxTotalSize: 15},
+			current:  5,
+			wantLeft: []string{"app.log", "app.log.2017-03-05.002", "app.log.bak", "other.log"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.MkdirAll("test", os.ModePerm)
+			defer os.RemoveAll("test")
+
+			setupRotatedFiles(t, files)
+
+			l := &fileLogger{
+				filename:       filepath.Join("test", "app.log"),
+				rotationConfig: tt.config,
+				currentSize:    tt.current,
+			}
+			l.naming, _ = newRotateNaming(l.filename, l.rotationConfig)
+			assert.Nil(t, l.deleteExcessFiles())
+			assert.Equal(t, tt.wantLeft, listFiles(t, "test"))
+			assert.Equal(t, []string{"app.log.2000-01-01"}, listFiles(t, filepath.Join("test", "sub")))
+		})
+	}
+}
+
+func Test_fileLogger_MaxBackups(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+	}{
+		{name: "default naming"},
+		{name: "pattern", pattern: "app-{date}-{seq}.log"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.MkdirAll("test", os.ModePerm)
+			defer os.RemoveAll("test")
+
+			l, err := FileIniter()("Test_fileLogger_MaxBackups", FileConfig{
+				Filename: filepath.Join("test", "app.log"),
+				FileRotationConfig: FileRotationConfig{
+					Rotate:          true,
+					MaxLines:        1,
+					MaxBackups:      3,
+					FilenamePattern: tt.pattern,
+				},
+			})
+			assert.Nil(t, err)
+			fl := l.(*fileLogger)
+			for i := 1; i <= 10; i++ {
+				_, err = fl.write(newMessage(LevelInfo, 0, "line %d", i))
+				assert.Nil(t, err)
+			}
+			assert.Nil(t, fl.Close())
+
+			// The newest rotated files survive.
+			files, err := fl.rotatedFiles()
+			assert.Nil(t, err)
+			var contents []string
+			for _, f := range files {
+				data, err := ioutil.ReadFile(f.path)
+				assert.Nil(t, err)
+				contents = append(contents, string(data))
+			}
+			if assert.Len(t, contents, 3) {
+				assert.Contains(t, contents[0], "line 10\n")
+				assert.Contains(t, contents[1], "line 9\n")
+				assert.Contains(t, contents[2], "line 8\n")
+			}
+		})
+	}
+}
+
+func Test_fileLogger_MaxLines_restart(t *testing.T) {
+	_ = os.MkdirAll("test", os.ModePerm)
+	defer os.RemoveAll("test")
+
+	filename := filepath.Join("test", "app.log")
+	assert.Nil(t, ioutil.WriteFile(filename, []byte("line 1\nline 2\n"), 0644))
+
+	l, err := FileIniter()("Test_fileLogger_MaxLines_restart", FileConfig{
+		Filename: filename,
+		FileRotationConfig: FileRotationConfig{
+			Rotate:   true,
+			MaxLines: 3,
+		},
+	})
+	assert.Nil(t, err)
+	fl := l.(*fileLogger)
+	defer fl.Close()
+	assert.Equal(t, int64(2), fl.currentLines)
+
+	// The file is rotated only after the third line is written.
+	_, err = fl.write(newMessage(LevelInfo, 0, "line 3"))
+	assert.Nil(t, err)
+	files, err := fl.rotatedFiles()
+	assert.Nil(t, err)
+	if assert.Len(t, files, 1) {
+		data, err := ioutil.ReadFile(files[0].path)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, strings.Count(string(data), "\n"))
+	}
+}
+
+func Test_compressFile(t *testing.T) {
+	_ = os.MkdirAll("test", os.ModePerm)
+	defer os.RemoveAll("test")
+
+	filename := filepath.Join("test", "Test_compressFile.log.2017-03-05")
+	assert.Nil(t, ioutil.WriteFile(filename, []byte("hello\nworld\n"), 0644))
+	modTime := time.Date(2017, 3, 5, 12, 0, 0, 0, time.UTC)
+	assert.Nil(t, os.Chtimes(filename, modTime, modTime))
+
+	assert.Nil(t, compressFile(filename))
+	assert.False(t, isExist(filename))
+
+	fi, err := os.Stat(filename + ".gz")
+	assert.Nil(t, err)
+	assert.True(t, modTime.Equal(fi.ModTime()))
+
+	f, err := os.Open(filename + ".gz")
+	assert.Nil(t, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	assert.Nil(t, err)
+	data, err := ioutil.ReadAll(gr)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello\nworld\n", string(data))
+
+	err = compressFile(filepath.Join("test", "404.log"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func Test_fileLogger_compress(t *testing.T) {
+	_ = os.MkdirAll("test", os.ModePerm)
+	defer os.RemoveAll("test")
+
+	filename := filepath.Join("test", "Test_fileLogger_compress.log")
+	l, err := FileIniter()("Test_fileLogger_compress", FileConfig{
+		Filename: filename,
+		FileRotationConfig: FileRotationConfig{
+			Rotate:   true,
+			MaxLines: 1,
+			Compress: true,
+		},
+	})
+	assert.Nil(t, err)
+	fl := l.(*fileLogger)
+
+	assert.Nil(t, fl.Write(newMessage(LevelInfo, 0, "first")))
+	assert.Nil(t, fl.Write(newMessage(LevelInfo, 0, "second")))
+	assert.Nil(t, fl.Close())
+
+	// Both lines are rotated and compressed, the current file is left as is.
+	rotated := filename + "." + time.Now().Format(simpleDateFormat)
+	assert.False(t, isExist(rotated))
+	assert.True(t, isExist(rotated+".gz"))
+	assert.False(t, isExist(rotated+".001"))
+	assert.True(t, isExist(rotated+".001.gz"))
+	assert.True(t, isExist(filename))
+	assert.False(t, isExist(filename+".gz"))
+}
+
+func Test_fileLogger_compressWithRetention(t *testing.T) {
+	_ = os.MkdirAll("test", os.ModePerm)
+	defer os.RemoveAll("test")
+
+	t.Run("files being compressed are not deleted", func(t *testing.T) {
+		setupRotatedFiles(t, map[string]int{
+			"app.log.2017-03-04": 10,
+			"app.log.2017-03-05": 10,
+		})
+		l := &fileLogger{
+			filename:         filepath.Join("test", "app.log"),
+			rotationConfig:   FileRotationConfig{MaxBackups: 1, MaxDays: 1},
+			compressingFiles: map[string]bool{filepath.Join("test", "app.log.2017-03-04"): true},
+		}
+		l.naming, _ = newRotateNaming(l.filename, l.rotationConfig)
+		assert.Nil(t, l.deleteRotatedFiles())
+		assert.Equal(t, []string{"app.log", "app.log.2017-03-04", "app.log.bak", "other.log"}, listFiles(t, "test"))
+	})
+
+	t.Run("rotate repeatedly", func(t *testing.T) {
+		l, err := FileIniter()("Test_fileLogger_compressWithRetention", FileConfig{
+			Filename: filepath.Join("test", "rotate.log"),
+			FileRotationConfig: FileRotationConfig{
+				Rotate:     true,
+				MaxLines:   1,
+				MaxBackups: 2,
+				Compress:   true,
+			},
+		})
+		assert.Nil(t, err)
+		fl := l.(*fileLogger)
+		for i := 1; i <= 20; i++ {
+			assert.Nil(t, fl.Write(newMessage(LevelInfo, 0, "line %d", i)))
+		}
+		assert.Nil(t, fl.Close())
+
+		// The newest file is compressed and kept.
+		files, err := fl.rotatedFiles()
+		assert.Nil(t, err)
+		if assert.NotEmpty(t, files) {
+			assert.True(t, strings.HasSuffix(files[0].path, ".gz"))
+
+			f, err := os.Open(files[0].path)
+			assert.Nil(t, err)
+			defer f.Close()
+			r, err := gzip.NewReader(f)
+			assert.Nil(t, err)
+			data, err := ioutil.ReadAll(r)
+			assert.Nil(t, err)
+			assert.Contains(t, string(data), "line 20\n")
+		}
+	})
+}
+
+func TestNewFileWithOptions(t *testing.T) {
+	_ = os.MkdirAll("test", os.ModePerm)
+	defer os.RemoveAll("test")
+
+	testName := "TestNewFileWithOptions"
+	defer Remove(testName)
+
+	filename := filepath.Join("test", "TestNewFileWithOptions.log")
+	err := NewFileWithOptions(testName,
+		WithLevel(LevelInfo),
+		WithFilename(filename),
+		WithFileRotation(FileRotationConfig{MaxBackups: 3}),
+	)
+	assert.Nil(t, err)
+
+	l, ok := mgr.lookup(testName)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, LevelInfo, l.Level())
+	fl := l.Logger.(*fileLogger)
+	assert.Equal(t, filename, fl.filename)
+	assert.Equal(t, FileRotationConfig{MaxBackups: 3}, fl.rotationConfig)
 }
@@ -0,0 +1,164 @@
+package clog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Formatter formats a message into bytes to be written by a logger.
+type Formatter interface {
+	// Format returns the formatted message, which must end with a newline.
+	Format(Messager) ([]byte, error)
+}
+
+const textTimeFormat = "2006/01/02 15:04:05"
+
+// formatTime formats t with given layout, or the default layout if empty.
+func formatTime(t time.Time, layout, defaultLayout string) string {
+	if layout == "" {
+		layout = defaultLayout
+	}
+	return t.Format(layout)
+}
+
+var _ Formatter = (*TextFormatter)(nil)
+
+// TextFormatter formats messages as human-readable text, e.g.
+//
+//	2006/01/02 15:04:05 [ERROR] [...er/main.go:64 main()] user login ip=127.0.0.1 user_id=42
+type TextFormatter struct {
+	// Layout of the message creation time, default is "2006/01/02 15:04:05".
+	TimeFormat string
+	// Format of the caller file path.
+	CallerPath CallerPath
+}
+
+// Format implements method of Formatter interface.
+func (f TextFormatter) Format(m Messager) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(formatTime(m.Time(), f.TimeFormat, textTimeFormat))
+	fmt.Fprintf(&buf, " [%5s] ", m.Level())
+	if c := m.Caller(); c != nil {
+		buf.WriteByte('[')
+		buf.WriteString(c.format(f.CallerPath))
+		buf.WriteString("] ")
+	}
+	buf.WriteString(withFields(m.Text(), m.Fields()))
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+var _ Formatter = (*rawFormatter)(nil)
+
+// rawFormatter formats messages as the time followed by the message string,
+// it is used by the standalone file writer.
+type rawFormatter struct{}
+
+func (rawFormatter) Format(m Messager) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(m.Time().Format(textTimeFormat))
+	buf.WriteByte(' ')
+	buf.WriteString(m.String())
+	if buf.Bytes()[buf.Len()-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+var _ Formatter = (*JSONFormatter)(nil)
+
+// JSONFormatter formats messages as JSON objects, one per line, e.g.
+//
+//	{"ip":"127.0.0.1","level":"info","msg":"user login","time":"2006-01-02T15:04:05.999999999Z","user_id":42}
+//
+// Fields are flattened into the object, keys that conflict with builtin ones
+// are prefixed with "fields.". Values that cannot be encoded as JSON are
+// rendered as strings with fmt.Sprint. The caller is rendered with keys
+// "caller" and "func" when available.
+type JSONFormatter struct {
+	// Layout of the message creation time, default is time.RFC3339Nano.
+	TimeFormat string
+	// Format of the caller file path.
+	CallerPath CallerPath
+}
+
+// Format implements method of Formatter interface.
+func (f JSONFormatter) Format(m Messager) ([]byte, error) {
+	fields := m.Fields()
+	obj := make(map[string]interface{}, len(fields)+5)
+	for k, v := range fields {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+
+		// Values that cannot be encoded (e.g. channels, functions, NaN) fall back
+		// to their default string form instead of dropping the whole message.
+		p, err := json.Marshal(v)
+		if err != nil {
+			p, _ = json.Marshal(fmt.Sprint(v))
+		}
+		obj[k] = json.RawMessage(p)
+	}
+
+	builtin := map[string]interface{}{
+		"time":  formatTime(m.Time(), f.TimeFormat, time.RFC3339Nano),
+		"level": strings.ToLower(m.Level().String()),
+		"msg":   m.Text(),
+	}
+	if c := m.Caller(); c != nil {
+		builtin["caller"] = fmt.Sprintf("%s:%d", c.Path(f.CallerPath), c.Line)
+		builtin["func"] = c.Function
+	}
+	for k, v := range builtin {
+		if fv, ok := obj[k]; ok {
+			obj["fields."+k] = fv
+		}
+		obj[k] = v
+	}
+
+	p, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %v", err)
+	}
+	return append(p, '\n'), nil
+}
+
+var _ Formatter = (*LogfmtFormatter)(nil)
+
+// LogfmtFormatter formats messages in logfmt style, e.g.
+//
+//	time=2006-01-02T15:04:05.999999999Z level=info msg="user login" ip=127.0.0.1 user_id=42
+//
+// The caller is rendered with keys "caller" and "func" when available.
+type LogfmtFormatter struct {
+	// Layout of the message creation time, default is time.RFC3339Nano.
+	TimeFormat string
+	// Format of the caller file path.
+	CallerPath CallerPath
+}
+
+// Format implements method of Formatter interface.
+func (f LogfmtFormatter) Format(m Messager) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("time=")
+	buf.WriteString(quoteFieldValue(formatTime(m.Time(), f.TimeFormat, time.RFC3339Nano)))
+	buf.WriteString(" level=")
+	buf.WriteString(strings.ToLower(m.Level().String()))
+	if c := m.Caller(); c != nil {
+		buf.WriteString(" caller=")
+		buf.WriteString(quoteFieldValue(fmt.Sprintf("%s:%d", c.Path(f.CallerPath), c.Line)))
+		buf.WriteString(" func=")
+		buf.WriteString(quoteFieldValue(c.Function))
+	}
+	buf.WriteString(" msg=")
+	buf.WriteString(quoteFieldValue(m.Text()))
+	if fields := m.Fields(); len(fields) > 0 {
+		buf.WriteByte(' ')
+		buf.WriteString(fields.String())
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
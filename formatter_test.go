@@ -0,0 +1,158 @@
+package clog
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextFormatter_Format(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  *message
+		want string
+	}{
+		{
+			name: "no fields",
+			msg:  newMessage(LevelInfo, 0, "user login"),
+			want: `^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} \[ INFO\] user login\n$`,
+		},
+		{
+			name: "has fields",
+			msg:  newMessage(LevelWarn, 0, "user login", Fields{"user_id": 42}),
+			want: `^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} \[ WARN\] user login user_id=42\n$`,
+		},
+		{
+			name: "has caller",
+			msg: &message{
+				level:  LevelError,
+				text:   "user login",
+				caller: &Caller{File: "/src/example/main.go", Line: 64, Function: "example.main"},
+			},
+			want: `^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} \[ERROR\] \[example/main.go:64 main\(\)\] user login\n$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := TextFormatter{CallerPath: CallerPathPackage}.Format(tt.msg)
+			assert.Nil(t, err)
+			assert.Regexp(t, regexp.MustCompile(tt.want), string(p))
+		})
+	}
+}
+
+func Test_rawFormatter_Format(t *testing.T) {
+	for _, body := range []string{"raw line", "raw line\n"} {
+		p, err := rawFormatter{}.Format(&message{body: body})
+		assert.Nil(t, err)
+		assert.Regexp(t, regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} raw line\n$`), string(p))
+	}
+}
+
+func TestJSONFormatter_Format(t *testing.T) {
+	msg := newMessage(LevelError, 1, "user %s login", "alice", Fields{
+		"user_id": 42,
+		"err":     errors.New("bad password"),
+		"level":   "conflict",
+	})
+	p, err := JSONFormatter{}.Format(msg)
+	assert.Nil(t, err)
+	assert.Equal(t, byte('\n'), p[len(p)-1])
+
+	var obj map[string]interface{}
+	assert.Nil(t, json.Unmarshal(p, &obj))
+	assert.NotEmpty(t, obj["time"])
+	delete(obj, "time")
+	assert.Contains(t, obj["caller"], "formatter_test.go:")
+	assert.Equal(t, "unknwon.dev/clog/v2.TestJSONFormatter_Format", obj["func"])
+	delete(obj, "caller")
+	delete(obj, "func")
+	assert.Equal(t,
+		map[string]interface{}{
+			"level":        "error",
+			"msg":          "user alice login",
+			"user_id":      float64(42),
+			"err":          "bad password",
+			"fields.level": "conflict",
+		},
+		obj,
+	)
+
+	p, err = JSONFormatter{}.Format(newMessage(LevelInfo, 0, "bad", Fields{
+		"nan":     math.NaN(),
+		"fn":      func() {},
+		"user_id": 42,
+	}))
+	assert.Nil(t, err)
+	obj = nil
+	assert.Nil(t, json.Unmarshal(p, &obj))
+	assert.Equal(t, "NaN", obj["nan"])
+	assert.IsType(t, "", obj["fn"])
+	assert.Equal(t, float64(42), obj["user_id"])
+	assert.Equal(t, "bad", obj["msg"])
+}
+
+func TestLogfmtFormatter_Format(t *testing.T) {
+	msg := newMessage(LevelInfo, 0, "user login", Fields{"user_id": 42, "ip": "127.0.0.1"})
+	p, err := LogfmtFormatter{}.Format(msg)
+	assert.Nil(t, err)
+	assert.Regexp(t,
+		regexp.MustCompile(`^time=\S+ level=info msg="user login" ip=127.0.0.1 user_id=42\n$`),
+		string(p),
+	)
+
+	msg = newMessage(LevelInfo, 0, "user login", Fields{"bad key": 1, "a=b": 2, "": 3})
+	p, err = LogfmtFormatter{}.Format(msg)
+	assert.Nil(t, err)
+	assert.Regexp(t,
+		regexp.MustCompile(`^time=\S+ level=info msg="user login" _=3 a_b=2 bad_key=1\n$`),
+		string(p),
+	)
+}
+
+func TestFormatter_time(t *testing.T) {
+	msg := &message{
+		level: LevelInfo,
+		text:  "user login",
+		time:  time.Date(2020, 11, 22, 8, 30, 15, 123456789, time.UTC),
+	}
+
+	tests := []struct {
+		name      string
+		formatter Formatter
+		want      string
+	}{
+		{
+			name:      "text",
+			formatter: TextFormatter{},
+			want:      "2020/11/22 08:30:15 [ INFO] user login\n",
+		},
+		{
+			name:      "text with custom time format",
+			formatter: TextFormatter{TimeFormat: "2006-01-02 15:04:05.000"},
+			want:      "2020-11-22 08:30:15.123 [ INFO] user login\n",
+		},
+		{
+			name:      "json",
+			formatter: JSONFormatter{},
+			want:      `{"level":"info","msg":"user login","time":"2020-11-22T08:30:15.123456789Z"}` + "\n",
+		},
+		{
+			name:      "logfmt",
+			formatter: LogfmtFormatter{TimeFormat: time.RFC1123},
+			want:      `time="Sun, 22 Nov 2020 08:30:15 UTC" level=info msg="user login"` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := tt.formatter.Format(msg)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, string(p))
+		})
+	}
+}
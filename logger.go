@@ -2,9 +2,15 @@ package clog
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
+	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/fatih/color"
 )
@@ -19,6 +25,33 @@ type Logger interface {
 	Write(Messager) error
 }
 
+// Starter is an optional interface for loggers that need to start background
+// work before accepting messages. Start is called once by New before the
+// logger is published, ctx is canceled once the logger is removed, replaced or
+// stopped. A logger that fails to start is not added to the managed list.
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Flusher is an optional interface for loggers that buffer written messages.
+// Flush is called by Manager.Flush after queued messages are written, and
+// before the logger is closed.
+type Flusher interface {
+	Flush() error
+}
+
+// Reopener is an optional interface for loggers that write to files. Reopen
+// is called by Manager.Reopen to reopen the files, e.g. after they are moved
+// by external rotation tools such as logrotate. It may be called concurrently
+// with Write.
+type Reopener interface {
+	Reopen() error
+}
+
+// Loggers may also implement io.Closer to release resources, Close is called
+// once the logger is removed, replaced or stopped and all of its queued
+// messages have been written.
+
 var _ Logger = (*noopLogger)(nil)
 
 type noopLogger struct {
@@ -36,22 +69,353 @@ func noopIniter(name string, _ ...interface{}) Initer {
 	}
 }
 
+// OverflowPolicy is the policy to apply when the buffer of a logger is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the write until there is room in the buffer.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest discards the message being written.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest message in the buffer to make
+	// room for the message being written. It behaves the same as
+	// OverflowDropNewest for loggers without buffer.
+	OverflowDropOldest
+	// OverflowBlockTimeout blocks the write until there is room in the buffer
+	// or the timeout is reached, then discards the message being written.
+	OverflowBlockTimeout
+)
+
+// DefaultDropReportInterval is the default interval to report the number of
+// dropped messages.
+const DefaultDropReportInterval = time.Minute
+
+// OverflowConfig is the config object for handling full buffer of a logger.
+// It is used by New and not passed to the initer.
+type OverflowConfig struct {
+	// Policy to apply when the buffer is full.
+	Policy OverflowPolicy
+	// Maximum duration to wait for room in the buffer, only used by
+	// OverflowBlockTimeout.
+	Timeout time.Duration
+	// Interval to write a warning to the logger when any message has been
+	// dropped since last report. Default is DefaultDropReportInterval.
+	ReportInterval time.Duration
+}
+
+// CallerLevel is the minimum level of messages to carry caller information
+// for a logger, it overrides the level set by SetCallerLevel. It is used by New
+// and not passed to the initer.
+type CallerLevel Level
+
+// loggerOptions are options of a logger handled by New.
+type loggerOptions struct {
+	bufferSize  int
+	overflow    OverflowConfig
+	callerLevel *Level
+	level       *Level
+}
+
+// Option is a typed option of a logger, it is used by New and not passed to
+// the initer.
+type Option func(*loggerOptions)
+
+// WithBufferSize sets the buffer size of the logger, default is 0.
+func WithBufferSize(size int) Option {
+	return func(o *loggerOptions) {
+		o.bufferSize = size
+	}
+}
+
+// WithLevel sets the minimum logging level of the logger, which overrides the
+// level set by the config object of the logger.
+func WithLevel(level Level) Option {
+	return func(o *loggerOptions) {
+		o.level = &level
+	}
+}
+
+// WithOverflow sets the config for handling full buffer of the logger.
+func WithOverflow(cfg OverflowConfig) Option {
+	return func(o *loggerOptions) {
+		o.overflow = cfg
+	}
+}
+
+// WithCallerLevel sets the minimum level of messages to carry caller
+// information for the logger, it overrides the level set by SetCallerLevel.
+func WithCallerLevel(level Level) Option {
+	return func(o *loggerOptions) {
+		o.callerLevel = &level
+	}
+}
+
 type cancelableLogger struct {
-	cancel  context.CancelFunc
-	msgChan chan Messager
-	done    chan struct{}
+	cancel   context.CancelFunc
+	msgChan  chan Messager
+	done     chan struct{}
+	overflow OverflowConfig
+	dropped  uint64 // Accessed atomically
+	errors   uint64 // Accessed atomically
+	level    int64  // Accessed atomically
+	// enqueued is the number of messages put into the msgChan, and processed
+	// is the number of them that have been written or dropped. Accessed
+	// atomically.
+	enqueued  uint64
+	processed uint64
+	// mutedUntil is the Unix time in nanoseconds until when the logger is
+	// muted, zero means not muted. Accessed atomically.
+	mutedUntil int64
+
+	// callerLevel is only used when hasCallerLevel is true.
+	callerLevel    Level
+	hasCallerLevel bool
+
+	// mu guards closed, next and sending to the msgChan, so no message is
+	// sent after the logger is released.
+	mu     sync.RWMutex
+	closed bool
+	// next is the logger replacing this one, messages sent after the release
+	// are forwarded to it instead of being lost.
+	next *cancelableLogger
+
+	// flushReq receives requests from Manager.Flush to flush the logger, it is
+	// served by the goroutine writing messages.
+	flushReq chan chan struct{}
+
+	// handleError is the error handler of the manager.
+	handleError ErrorHandler
+
 	Logger
 }
 
+// ErrorHandler handles an error that occurred while processing a message. The
+// name is the name of the logger and m is the message, they are empty when not
+// applicable, e.g. a failed Close of a logger has no message, and an error of
+// reloading the configuration has neither.
+type ErrorHandler func(name string, m Messager, err error)
+
 var errLogger = log.New(color.Output, "", log.Ldate|log.Ltime)
 var errSprintf = color.New(color.FgRed).Sprintf
 
-func (l *cancelableLogger) error(err error) {
+// printError is the default error handler, which prints errors in red to the
+// standard output.
+func printError(name string, _ Messager, err error) {
+	if name == "" {
+		errLogger.Print(errSprintf("[clog] %v", err))
+		return
+	}
+	errLogger.Print(errSprintf("[clog] [%s]: %v", name, err))
+}
+
+func (l *cancelableLogger) error(m Messager, err error) {
 	if err == nil {
 		return
 	}
+	atomic.AddUint64(&l.errors, 1)
+
+	l.handleError(l.Name(), m, err)
+}
+
+// Level returns the current minimum logging level of the logger, which
+// overrides the level reported by the underlying logger.
+func (l *cancelableLogger) Level() Level {
+	return Level(atomic.LoadInt64(&l.level))
+}
+
+// accepts returns true if the logger accepts messages in given level.
+func (l *cancelableLogger) accepts(level Level) bool {
+	if l.Level() > level {
+		return false
+	}
+
+	mutedUntil := atomic.LoadInt64(&l.mutedUntil)
+	return mutedUntil == 0 || time.Now().UnixNano() >= mutedUntil
+}
+
+// send sends the message to the logger with respect to its overflow policy.
+// Once the logger has been released, the message is forwarded to the logger
+// replacing it if any, otherwise it is discarded.
+func (l *cancelableLogger) send(m Messager) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.closed {
+		if l.next != nil && l.next.accepts(m.Level()) {
+			l.next.send(m)
+		}
+		return
+	}
+
+	policy := l.overflow.Policy
+	if policy == OverflowDropOldest && cap(l.msgChan) == 0 {
+		policy = OverflowDropNewest
+	}
+
+	switch policy {
+	case OverflowDropNewest:
+		select {
+		case l.msgChan <- m:
+			atomic.AddUint64(&l.enqueued, 1)
+		default:
+			atomic.AddUint64(&l.dropped, 1)
+		}
+
+	case OverflowDropOldest:
+		for {
+			select {
+			case l.msgChan <- m:
+				atomic.AddUint64(&l.enqueued, 1)
+				return
+			default:
+			}
+
+			select {
+			case <-l.msgChan:
+				atomic.AddUint64(&l.dropped, 1)
+				atomic.AddUint64(&l.processed, 1)
+			default:
+			}
+		}
+
+	case OverflowBlockTimeout:
+		select {
+		case l.msgChan <- m:
+			atomic.AddUint64(&l.enqueued, 1)
+			return
+		default:
+		}
+
+		timer := time.NewTimer(l.overflow.Timeout)
+		defer timer.Stop()
+		select {
+		case l.msgChan <- m:
+			atomic.AddUint64(&l.enqueued, 1)
+		case <-timer.C:
+			atomic.AddUint64(&l.dropped, 1)
+		}
+
+	default:
+		l.msgChan <- m
+		atomic.AddUint64(&l.enqueued, 1)
+	}
+}
+
+// flush flushes the logger if it implements Flusher.
+func (l *cancelableLogger) flush() {
+	if f, ok := l.Logger.(Flusher); ok {
+		if err := f.Flush(); err != nil {
+			l.error(nil, fmt.Errorf("flush: %v", err))
+		}
+	}
+}
+
+// close flushes and closes the logger if it implements io.Closer.
+func (l *cancelableLogger) close() {
+	l.flush()
+	if c, ok := l.Logger.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			l.error(nil, fmt.Errorf("close: %v", err))
+		}
+	}
+}
+
+// write writes the message taken from the msgChan.
+func (l *cancelableLogger) write(m Messager) {
+	l.error(m, l.Write(m))
+	atomic.AddUint64(&l.processed, 1)
+}
+
+// reportDropped writes a warning to the logger if there are more dropped
+// messages than the last reported number, and returns the latest number.
+func (l *cancelableLogger) reportDropped(reported uint64) uint64 {
+	dropped := atomic.LoadUint64(&l.dropped)
+	if dropped <= reported {
+		return reported
+	}
+
+	m := newMessage(LevelWarn, 0, "[clog] dropped %d messages due to full buffer", dropped-reported,
+		Fields{"dropped_total": dropped},
+	)
+	l.error(m, l.Write(m))
+	return dropped
+}
+
+// release stops accepting new messages and waits until queued messages are
+// drained. It is safe to be called multiple times.
+func (l *cancelableLogger) release() {
+	l.releaseTo(nil)
+}
+
+// releaseTo is like release, but messages sent after the release are
+// forwarded to next.
+func (l *cancelableLogger) releaseTo(next *cancelableLogger) {
+	l.stop(next)
+	<-l.done
+}
+
+// stop stops accepting new messages and starts draining queued messages
+// without waiting, messages sent afterwards are forwarded to next if not nil.
+// The done channel is closed once the logger is drained.
+func (l *cancelableLogger) stop(next *cancelableLogger) {
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		l.next = next
+	}
+	l.mu.Unlock()
+
+	l.cancel()
+}
+
+// run writes messages from the msgChan until ctx is done. When prev is not
+// nil, it waits for prev to be closed before writing any message, so messages
+// queued by the replaced logger are written first.
+func (l *cancelableLogger) run(ctx context.Context, prev <-chan struct{}) {
+	if prev != nil {
+		<-prev
+	}
+
+	var reportTick <-chan time.Time
+	if l.overflow.Policy != OverflowBlock {
+		interval := l.overflow.ReportInterval
+		if interval <= 0 {
+			interval = DefaultDropReportInterval
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		reportTick = ticker.C
+	}
+
+	var reported uint64
+loop:
+	for {
+		select {
+		case m := <-l.msgChan:
+			l.write(m)
+		case <-reportTick:
+			reported = l.reportDropped(reported)
+		case reply := <-l.flushReq:
+			l.flush()
+			close(reply)
+		case <-ctx.Done():
+			break loop
+		}
+	}
 
-	errLogger.Print(errSprintf("[clog] [%s]: %v", l.Name(), err))
+	// Drain the msgChan at best effort
+	for {
+		if len(l.msgChan) == 0 {
+			break
+		}
+
+		l.write(<-l.msgChan)
+	}
+	l.reportDropped(reported)
+	l.close()
+
+	// Notify the cleanup is done
+	close(l.done)
 }
 
 const (
@@ -59,76 +423,476 @@ const (
 	stateRunning
 )
 
-type manager struct {
-	state         int64
-	ctx           context.Context
-	cancel        context.CancelFunc
-	loggers       []*cancelableLogger
-	loggersByName map[string]*cancelableLogger
+// loggerSet is an immutable snapshot of managed loggers.
+type loggerSet struct {
+	list   []*cancelableLogger
+	byName map[string]*cancelableLogger
+}
+
+// Manager manages a list of loggers and dispatches messages to them. The
+// package-level functions operate on a default manager, use NewManager to
+// create isolated ones, e.g. for a library or for tests.
+//
+// All methods of a Manager are safe for concurrent use.
+type Manager struct {
+	state       int64
+	callerLevel int64        // Accessed atomically
+	location    atomic.Value // *time.Location
+	errHandler  atomic.Value // ErrorHandler
+	extractors  atomic.Value // []ContextExtractor
+	ctx         context.Context
+	cancel      context.CancelFunc
+
+	// mu serializes modifications of the logger set, readers load the latest
+	// snapshot from set without locking.
+	mu  sync.Mutex
+	set atomic.Value // *loggerSet
+
+	// configMu serializes applying configurations, configs holds the applied
+	// config of each logger created from a configuration.
+	configMu sync.Mutex
+	configs  map[string]appliedConfig
+}
+
+// NewManager returns a new manager with no logger.
+func NewManager() *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		state:       stateRunning,
+		callerLevel: int64(LevelError),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	m.errHandler.Store(ErrorHandler(printError))
+	m.set.Store(&loggerSet{
+		byName: make(map[string]*cancelableLogger),
+	})
+	return m
+}
+
+// loggers returns the current snapshot of managed loggers, the returned slice
+// must not be modified.
+func (m *Manager) loggers() []*cancelableLogger {
+	return m.set.Load().(*loggerSet).list
+}
+
+// lookup returns the logger with given name in the current snapshot.
+func (m *Manager) lookup(name string) (*cancelableLogger, bool) {
+	l, ok := m.set.Load().(*loggerSet).byName[name]
+	return l, ok
+}
+
+// store replaces the current snapshot with given list of loggers. It must be
+// called with m.mu held.
+func (m *Manager) store(list []*cancelableLogger) {
+	byName := make(map[string]*cancelableLogger, len(list))
+	for _, l := range list {
+		byName[l.Name()] = l
+	}
+	m.set.Store(&loggerSet{
+		list:   list,
+		byName: byName,
+	})
+}
+
+func (m *Manager) len() int {
+	return len(m.loggers())
+}
+
+// SetCallerLevel sets the minimum level of messages to carry caller
+// information, default is LevelError. Loggers created with CallerLevel are not
+// affected.
+func (m *Manager) SetCallerLevel(level Level) {
+	atomic.StoreInt64(&m.callerLevel, int64(level))
+}
+
+// SetCallerLevel sets the minimum level of messages to carry caller
+// information for the default manager, default is LevelError.
+func SetCallerLevel(level Level) {
+	mgr.SetCallerLevel(level)
+}
+
+// SetTimeLocation sets the time zone of message creation time, default is
+// time.Local.
+func (m *Manager) SetTimeLocation(loc *time.Location) {
+	m.location.Store(loc)
+}
+
+// SetErrorHandler sets the handler for errors that occurred while processing
+// messages, e.g. failed writes of loggers and messages sent to unavailable
+// loggers. The default handler prints errors to the standard output, pass nil
+// to restore it. The handler may be called concurrently.
+func (m *Manager) SetErrorHandler(h ErrorHandler) {
+	if h == nil {
+		h = printError
+	}
+	m.errHandler.Store(h)
+}
+
+// SetErrorHandler sets the handler for errors that occurred while processing
+// messages for the default manager.
+func SetErrorHandler(h ErrorHandler) {
+	mgr.SetErrorHandler(h)
+}
+
+// handleError passes the error to the error handler.
+func (m *Manager) handleError(name string, msg Messager, err error) {
+	m.errHandler.Load().(ErrorHandler)(name, msg, err)
 }
 
-func (m *manager) len() int {
-	return len(m.loggers)
+// SetTimeLocation sets the time zone of message creation time for the default
+// manager, default is time.Local.
+func SetTimeLocation(loc *time.Location) {
+	mgr.SetTimeLocation(loc)
+}
+
+// localize converts the message creation time to the configured time zone.
+func (m *Manager) localize(msg *message) *message {
+	if loc, ok := m.location.Load().(*time.Location); ok && loc != nil {
+		msg.time = msg.time.In(loc)
+	}
+	return msg
+}
+
+// wantsCaller returns true if the logger wants caller information for
+// messages in given level.
+func (m *Manager) wantsCaller(l *cancelableLogger, level Level) bool {
+	if l.hasCallerLevel {
+		return level >= l.callerLevel
+	}
+	return level >= Level(atomic.LoadInt64(&m.callerLevel))
 }
 
 // write attempts to send message to all loggers.
-func (m *manager) write(level Level, skip int, format string, v ...interface{}) {
-	if mgr.len() == 0 {
-		errLogger.Print(errSprintf("[clog] no logger is available"))
+func (m *Manager) write(level Level, skip int, format string, v ...interface{}) {
+	loggers := m.loggers()
+	if len(loggers) == 0 {
+		m.handleError("", m.localize(newMessage(level, skip, format, v...)), errors.New("no logger is available"))
 		return
 	}
 
+	// Only capture the caller when any logger wants it.
+	capture := false
+	for i := range loggers {
+		if loggers[i].accepts(level) && m.wantsCaller(loggers[i], level) {
+			capture = true
+			break
+		}
+	}
+	if !capture {
+		skip = 0
+	}
+
 	var msg *message
-	for i := range mgr.loggers {
-		if mgr.loggers[i].Level() > level {
+	for i := range loggers {
+		if !loggers[i].accepts(level) {
 			continue
 		}
 
 		if msg == nil {
-			msg = newMessage(level, skip, format, v...)
+			msg = m.localize(newMessage(level, skip, format, v...))
 		}
 
-		mgr.loggers[i].msgChan <- msg
+		if m.wantsCaller(loggers[i], level) {
+			loggers[i].send(msg)
+		} else {
+			loggers[i].send(msg.withoutCaller())
+		}
 	}
 }
 
 // writeTo attempts to send message to the logger with given name.
-func (m *manager) writeTo(name string, level Level, skip int, format string, v ...interface{}) {
-	l, ok := mgr.loggersByName[name]
+func (m *Manager) writeTo(name string, level Level, skip int, format string, v ...interface{}) {
+	l, ok := m.lookup(name)
 	if !ok {
-		errLogger.Print(errSprintf("[clog] logger with name %q is not available", name))
+		m.handleError(name, m.localize(newMessage(level, skip, format, v...)), errors.New("logger is not available"))
 		return
 	}
 
-	if l.Level() > level {
+	if !l.accepts(level) {
 		return
 	}
 
-	l.msgChan <- newMessage(level, skip, format, v...)
+	if !m.wantsCaller(l, level) {
+		skip = 0
+	}
+	l.send(m.localize(newMessage(level, skip, format, v...)))
+}
+
+// DrainError is the error returned when some loggers have not written all of
+// their queued messages before the context is done.
+type DrainError struct {
+	// Names of loggers that have not been drained.
+	Loggers []string
+	// Err is the error of the context.
+	Err error
 }
 
-func (m *manager) stop() {
+func (e *DrainError) Error() string {
+	return fmt.Sprintf("loggers not drained: %s: %v", strings.Join(e.Loggers, ", "), e.Err)
+}
+
+// ErrStopped is returned when creating a logger with a stopped manager.
+var ErrStopped = errors.New("manager has been stopped")
+
+// stopped returns true if the manager has been stopped.
+func (m *Manager) stopped() bool {
+	return atomic.LoadInt64(&m.state) != stateRunning
+}
+
+// Stop propagates cancellation to all loggers and waits for completion.
+// Creating loggers afterwards fails with ErrStopped until the manager is
+// Reset.
+func (m *Manager) Stop() {
+	_ = m.StopContext(context.Background())
+}
+
+// StopContext propagates cancellation to all loggers and waits for completion
+// until ctx is done. A *DrainError is returned with names of loggers that
+// have not been drained in time, they keep draining in the background.
+func (m *Manager) StopContext(ctx context.Context) error {
 	// Make sure cancellation is only propagated once to prevent deadlock of WaitForStop.
 	if !atomic.CompareAndSwapInt64(&m.state, stateRunning, stateStopping) {
-		return
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer m.cancel()
+
+	// Let all loggers drain at the same time.
+	loggers := m.loggers()
+	for _, l := range loggers {
+		l.stop(nil)
+	}
+
+	var pending []string
+	for _, l := range loggers {
+		select {
+		case <-l.done:
+			continue
+		default:
+		}
+
+		select {
+		case <-l.done:
+		case <-ctx.Done():
+			pending = append(pending, l.Name())
+		}
 	}
+	if len(pending) > 0 {
+		return &DrainError{
+			Loggers: pending,
+			Err:     ctx.Err(),
+		}
+	}
+	return nil
+}
+
+// flushPollInterval is the interval to check progress of loggers by Flush.
+const flushPollInterval = 5 * time.Millisecond
+
+// Flush waits until all messages queued by the time of the call are written,
+// then flushes loggers that implement Flusher, without stopping the loggers.
+// A *DrainError is returned with names of loggers that have not been flushed
+// when ctx is done.
+func (m *Manager) Flush(ctx context.Context) error {
+	loggers := m.loggers()
+	targets := make([]uint64, len(loggers))
+	for i, l := range loggers {
+		targets[i] = atomic.LoadUint64(&l.enqueued)
+	}
+
+	ticker := time.NewTicker(flushPollInterval)
+	defer ticker.Stop()
+	for {
+		var pending []string
+		for i, l := range loggers {
+			if atomic.LoadUint64(&l.processed) < targets[i] {
+				pending = append(pending, l.Name())
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
 
-	m.cancel()
-	for _, l := range m.loggers {
-		<-l.done
+		select {
+		case <-ctx.Done():
+			return &DrainError{
+				Loggers: pending,
+				Err:     ctx.Err(),
+			}
+		case <-ticker.C:
+		}
 	}
+
+	var pending []string
+	for _, l := range loggers {
+		if _, ok := l.Logger.(Flusher); !ok {
+			continue
+		}
+
+		// A logger that has been released is flushed before being closed.
+		reply := make(chan struct{})
+		select {
+		case l.flushReq <- reply:
+		case <-l.done:
+			continue
+		case <-ctx.Done():
+			pending = append(pending, l.Name())
+			continue
+		}
+
+		select {
+		case <-reply:
+		case <-ctx.Done():
+			pending = append(pending, l.Name())
+		}
+	}
+	if len(pending) > 0 {
+		return &DrainError{
+			Loggers: pending,
+			Err:     ctx.Err(),
+		}
+	}
+	return nil
 }
 
-var mgr *manager
+// Reset stops the manager if it is running, then removes all loggers and
+// returns the manager to the running state, so it can be used as if newly
+// created. Settings of the manager, e.g. the caller level, the time location
+// and registered context extractors, are kept.
+func (m *Manager) Reset() {
+	m.Stop()
 
-func init() {
-	ctx, cancel := context.WithCancel(context.Background())
-	mgr = &manager{
-		state:         stateRunning,
-		ctx:           ctx,
-		cancel:        cancel,
-		loggersByName: make(map[string]*cancelableLogger),
+	m.configMu.Lock()
+	defer m.configMu.Unlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m.store(nil)
+	m.configs = nil
+	atomic.StoreInt64(&m.state, stateRunning)
+}
+
+// mgr is the default manager used by package-level functions.
+var mgr = NewManager()
+
+// Default returns the default manager used by package-level functions.
+func Default() *Manager {
+	return mgr
+}
+
+// SetLevel changes the minimum logging level of the logger with given name
+// while it is running.
+func (m *Manager) SetLevel(name string, level Level) error {
+	l, ok := m.lookup(name)
+	if !ok {
+		return fmt.Errorf("logger with name %q is not available", name)
 	}
+
+	atomic.StoreInt64(&l.level, int64(level))
+	return nil
+}
+
+// SetLevel changes the minimum logging level of the logger with given name of
+// the default manager while it is running.
+func SetLevel(name string, level Level) error {
+	return mgr.SetLevel(name, level)
+}
+
+// Mute stops the logger with given name from accepting new messages for given
+// duration, or until Unmute is called if the duration is not positive.
+func (m *Manager) Mute(name string, d time.Duration) error {
+	l, ok := m.lookup(name)
+	if !ok {
+		return fmt.Errorf("logger with name %q is not available", name)
+	}
+
+	until := int64(math.MaxInt64)
+	if d > 0 {
+		until = time.Now().Add(d).UnixNano()
+	}
+	atomic.StoreInt64(&l.mutedUntil, until)
+	return nil
+}
+
+// Unmute makes the logger with given name accept new messages again.
+func (m *Manager) Unmute(name string) error {
+	l, ok := m.lookup(name)
+	if !ok {
+		return fmt.Errorf("logger with name %q is not available", name)
+	}
+
+	atomic.StoreInt64(&l.mutedUntil, 0)
+	return nil
+}
+
+// LoggerInfo is a snapshot of the state of a managed logger.
+type LoggerInfo struct {
+	// Name of the logger.
+	Name string
+	// Mode of the logger, which is reported by the Mode method of the logger
+	// if implemented, otherwise the type name of the logger.
+	Mode string
+	// Current minimum logging level.
+	Level Level
+	// Number of messages in the buffer and the buffer size.
+	QueueLength   int
+	QueueCapacity int
+	// Number of failed writes.
+	WriteErrors uint64
+	// Number of dropped messages due to full buffer.
+	Dropped uint64
+	// Whether the logger is muted.
+	Muted bool
+	// Time until when the logger is muted, zero value means muted
+	// indefinitely if Muted is true.
+	MutedUntil time.Time
+}
+
+func (l *cancelableLogger) info() LoggerInfo {
+	mode := fmt.Sprintf("%T", l.Logger)
+	if moder, ok := l.Logger.(interface{ Mode() string }); ok {
+		mode = moder.Mode()
+	}
+
+	info := LoggerInfo{
+		Name:          l.Name(),
+		Mode:          mode,
+		Level:         l.Level(),
+		QueueLength:   len(l.msgChan),
+		QueueCapacity: cap(l.msgChan),
+		WriteErrors:   atomic.LoadUint64(&l.errors),
+		Dropped:       atomic.LoadUint64(&l.dropped),
+	}
+	if mutedUntil := atomic.LoadInt64(&l.mutedUntil); mutedUntil > 0 && time.Now().UnixNano() < mutedUntil {
+		info.Muted = true
+		if mutedUntil != math.MaxInt64 {
+			info.MutedUntil = time.Unix(0, mutedUntil)
+		}
+	}
+	return info
+}
+
+// Loggers returns the state of all managed loggers.
+func (m *Manager) Loggers() []LoggerInfo {
+	loggers := m.loggers()
+	infos := make([]LoggerInfo, len(loggers))
+	for i := range loggers {
+		infos[i] = loggers[i].info()
+	}
+	return infos
+}
+
+// LoggerInfo returns the state of the logger with given name.
+func (m *Manager) LoggerInfo(name string) (LoggerInfo, bool) {
+	l, ok := m.lookup(name)
+	if !ok {
+		return LoggerInfo{}, false
+	}
+	return l.info(), true
 }
 
 // Initer takes a name and arbitrary number of parameters needed for initalization
@@ -136,107 +900,154 @@ func init() {
 type Initer func(string, ...interface{}) (Logger, error)
 
 // New initializes and appends a new logger to the managed list.
-// Calling this function multiple times will overwrite previous initialized
-// logger with the same name.
+// Calling this method multiple times will overwrite previous initialized
+// logger with the same name. No message is lost or reordered during the
+// replacement: the new logger starts to write after queued messages of the
+// previous logger are drained, and messages that arrive at the previous logger
+// in the meantime are forwarded to the new logger.
 //
-// Any integer type (i.e. int, int32, int64) will be used as buffer size.
-// Otherwise, the value will be passed to the initer.
-//
-// NOTE: This function is not concurrent safe.
-func New(name string, initer Initer, opts ...interface{}) error {
-	bufferSize := 0
-
-	vs := opts[:0]
+// Options of the Option type are applied in order, e.g. WithBufferSize and
+// WithLevel. For backward compatibility, any integer type (i.e. int, int32,
+// int64) will be used as buffer size, OverflowConfig or OverflowPolicy will be
+// used to handle full buffer, and CallerLevel will be used to capture caller
+// information. Otherwise, the value will be passed to the initer.
+func (m *Manager) New(name string, initer Initer, opts ...interface{}) error {
+	var o loggerOptions
+	vs := make([]interface{}, 0, len(opts))
 	for i := range opts {
 		switch opt := opts[i].(type) {
+		case Option:
+			opt(&o)
 		case int:
-			bufferSize = opt
+			o.bufferSize = opt
 		case int32:
-			bufferSize = int(opt)
+			o.bufferSize = int(opt)
 		case int64:
-			bufferSize = int(opt)
+			o.bufferSize = int(opt)
+		case OverflowConfig:
+			o.overflow = opt
+		case OverflowPolicy:
+			o.overflow.Policy = opt
+		case CallerLevel:
+			level := Level(opt)
+			o.callerLevel = &level
 		default:
 			vs = append(vs, opt)
 		}
 	}
 
+	if m.stopped() {
+		return ErrStopped
+	}
+
 	l, err := initer(name, vs...)
 	if err != nil {
 		return fmt.Errorf("initialize logger: %v", err)
 	}
 
-	if bufferSize < 0 {
-		bufferSize = 0
+	if o.bufferSize < 0 {
+		o.bufferSize = 0
 	}
 
-	ctx, cancel := context.WithCancel(mgr.ctx)
 	cl := &cancelableLogger{
-		cancel:  cancel,
-		msgChan: make(chan Messager, bufferSize),
-		done:    make(chan struct{}),
-		Logger:  l,
-	}
+		msgChan:  make(chan Messager, o.bufferSize),
+		done:     make(chan struct{}),
+		overflow: o.overflow,
+		level:    int64(l.Level()),
+		flushReq: make(chan chan struct{}),
+		Logger:   l,
 
-	// Check and replace previous logger
-	found := false
-	for i, l := range mgr.loggers {
-		if l.Name() == name {
-			found = true
+		handleError: m.handleError,
+	}
+	if o.level != nil {
+		cl.level = int64(*o.level)
+	}
+	if o.callerLevel != nil {
+		cl.callerLevel = *o.callerLevel
+		cl.hasCallerLevel = true
+	}
 
-			// Release previous logger
-			l.cancel()
-			<-l.done
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-			mgr.loggers[i] = cl
-			break
-		}
-	}
-	if !found {
-		mgr.loggers = append(mgr.loggers, cl)
+	// The manager may be stopped while initializing the logger.
+	if m.stopped() {
+		cl.close()
+		return ErrStopped
 	}
-	mgr.loggersByName[name] = cl
 
-	go func() {
-	loop:
-		for {
-			select {
-			case m := <-cl.msgChan:
-				cl.error(cl.Write(m))
-			case <-ctx.Done():
-				break loop
-			}
+	var ctx context.Context
+	ctx, cl.cancel = context.WithCancel(m.ctx)
+	if s, ok := l.(Starter); ok {
+		if err = s.Start(ctx); err != nil {
+			cl.cancel()
+			cl.close()
+			return fmt.Errorf("start logger: %v", err)
 		}
+	}
 
-		// Drain the msgChan at best effort
-		for {
-			if len(cl.msgChan) == 0 {
-				break
-			}
-
-			cl.error(cl.Write(<-cl.msgChan))
+	// Check and replace previous logger
+	loggers := m.loggers()
+	list := make([]*cancelableLogger, 0, len(loggers)+1)
+	var prev *cancelableLogger
+	for _, l := range loggers {
+		if l.Name() == name {
+			prev = l
+			list = append(list, cl)
+			continue
 		}
+		list = append(list, l)
+	}
+	if prev == nil {
+		list = append(list, cl)
+		go cl.run(ctx, nil)
+		m.store(list)
+		return nil
+	}
 
-		// Notify the cleanup is done
-		cl.done <- struct{}{}
-	}()
+	// Publish the new logger before releasing the previous one, so writers
+	// switch over as soon as possible.
+	go cl.run(ctx, prev.done)
+	m.store(list)
+	prev.releaseTo(cl)
 	return nil
 }
 
-// Remove removes a logger with given name from the managed list.
+// New initializes and appends a new logger to the managed list of the default
+// manager. Calling this function multiple times will overwrite previous
+// initialized logger with the same name.
 //
-// NOTE: This function is not concurrent safe.
-func Remove(name string) {
-	loggers := mgr.loggers[:0]
-	for _, l := range mgr.loggers {
+// See Manager.New for the options.
+func New(name string, initer Initer, opts ...interface{}) error {
+	return mgr.New(name, initer, opts...)
+}
+
+// Remove removes a logger with given name from the managed list and waits
+// until its queued messages are drained.
+func (m *Manager) Remove(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	loggers := m.loggers()
+	list := make([]*cancelableLogger, 0, len(loggers))
+	var removed *cancelableLogger
+	for _, l := range loggers {
 		if l.Name() == name {
-			go func(l *cancelableLogger) {
-				l.cancel()
-				<-l.done
-			}(l)
+			removed = l
 			continue
 		}
-		loggers = append(loggers, l)
+		list = append(list, l)
+	}
+	if removed == nil {
+		return
 	}
-	mgr.loggers = loggers
-	delete(mgr.loggersByName, name)
+
+	m.store(list)
+	removed.release()
+}
+
+// Remove removes a logger with given name from the managed list of the default
+// manager and waits until its queued messages are drained.
+func Remove(name string) {
+	mgr.Remove(name)
 }
@@ -1,8 +1,14 @@
 package clog
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -68,6 +74,34 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestManager_New_options(t *testing.T) {
+	m := NewManager()
+	defer m.Stop()
+
+	err := m.New("console", ConsoleIniter(),
+		ConsoleConfig{Level: LevelInfo},
+		WithBufferSize(10),
+		WithLevel(LevelWarn),
+		WithOverflow(OverflowConfig{Policy: OverflowDropOldest}),
+		WithCallerLevel(LevelTrace),
+		WithConsoleFormatter(JSONFormatter{}),
+	)
+	assert.Nil(t, err)
+
+	l, ok := m.lookup("console")
+	assert.True(t, ok)
+	assert.Equal(t, 10, cap(l.msgChan))
+	assert.Equal(t, LevelWarn, l.Level())
+	assert.Equal(t, OverflowDropOldest, l.overflow.Policy)
+	assert.True(t, l.hasCallerLevel)
+	assert.Equal(t, LevelTrace, l.callerLevel)
+	assert.Equal(t, JSONFormatter{}, l.Logger.(*consoleLogger).formatter)
+
+	// Options of another mode are rejected by the initer.
+	err = m.New("console", ConsoleIniter(), WithFilename("clog.log"))
+	assert.Equal(t, errors.New("initialize logger: unrecognized option of type 'clog.FileOption'"), err)
+}
+
 func TestRemove(t *testing.T) {
 	test1 := "TestRemove_1"
 	test1Initer := noopIniter(test1)
@@ -105,3 +139,434 @@ func TestRemove(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_concurrentReconfiguration(t *testing.T) {
+	m := NewManager()
+	defer m.Stop()
+
+	const (
+		numWriters = 8
+		numNames   = 4
+		numRounds  = 50
+	)
+
+	// Make sure there is always at least one logger available.
+	assert.Nil(t, m.New("base", noopIniter("base")))
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < numWriters; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				m.Info("writer %d", i)
+				m.WarnTo("base", "writer %d", i)
+			}
+		}(i)
+	}
+
+	for i := 0; i < numRounds; i++ {
+		name := fmt.Sprintf("logger%d", i%numNames)
+		assert.Nil(t, m.New(name, noopIniter(name), i%3))
+		if i%2 == 0 {
+			m.Remove(fmt.Sprintf("logger%d", (i+1)%numNames))
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+
+	for i := 0; i < numNames; i++ {
+		m.Remove(fmt.Sprintf("logger%d", i))
+	}
+	assert.Equal(t, 1, m.len())
+}
+
+func TestManager_New_replaceWithoutLoss(t *testing.T) {
+	m := NewManager()
+
+	const (
+		numWriters  = 4
+		numMessages = 2000
+	)
+	c := make(chan string, numWriters*numMessages)
+	initer := chanLoggerIniter("chan", LevelTrace)
+	assert.Nil(t, m.New("chan", initer, chanConfig{c}))
+
+	// Messages sent to a replaced logger are forwarded to the new one.
+	prev, _ := m.lookup("chan")
+	assert.Nil(t, m.New("chan", initer, chanConfig{c}))
+	prev.send(newMessage(LevelInfo, 0, "late"))
+	select {
+	case s := <-c:
+		assert.Equal(t, "[ INFO] late", s)
+	case <-time.After(time.Second):
+		t.Fatal("message sent to the replaced logger is lost")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < numWriters; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for n := 0; n < numMessages; n++ {
+				m.Info("%d %d", i, n)
+			}
+		}(i)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for i := 0; ; i++ {
+		select {
+		case <-done:
+		default:
+			assert.Nil(t, m.New("chan", initer, chanConfig{c}, i%3*10))
+			continue
+		}
+		break
+	}
+	m.Stop()
+	close(c)
+
+	next := make([]int, numWriters)
+	for s := range c {
+		var i, n int
+		_, err := fmt.Sscanf(s, "[ INFO] %d %d", &i, &n)
+		assert.Nil(t, err)
+		assert.Equal(t, next[i], n, "message of writer %d", i)
+		next[i] = n + 1
+	}
+	for i := range next {
+		assert.Equal(t, numMessages, next[i], "messages of writer %d", i)
+	}
+}
+
+var _ Logger = (*blockingLogger)(nil)
+
+// blockingLogger blocks every write until unblock is closed.
+type blockingLogger struct {
+	*noopLogger
+	started chan struct{}
+	unblock chan struct{}
+	written chan string
+}
+
+func (l *blockingLogger) Write(m Messager) error {
+	l.started <- struct{}{}
+	<-l.unblock
+	l.written <- m.String()
+	return nil
+}
+
+func TestManager_overflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		overflow interface{}
+		want     []string
+	}{
+		{
+			name:     "drop newest",
+			overflow: OverflowDropNewest,
+			want:     []string{"[ INFO] 1", "[ INFO] 2", "[ WARN] [clog] dropped 1 messages due to full buffer"},
+		},
+		{
+			name:     "drop oldest",
+			overflow: OverflowDropOldest,
+			want:     []string{"[ INFO] 1", "[ INFO] 3", "[ WARN] [clog] dropped 1 messages due to full buffer"},
+		},
+		{
+			name: "block with timeout",
+			overflow: OverflowConfig{
+				Policy:  OverflowBlockTimeout,
+				Timeout: 10 * time.Millisecond,
+			},
+			want: []string{"[ INFO] 1", "[ INFO] 2", "[ WARN] [clog] dropped 1 messages due to full buffer"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager()
+
+			l := &blockingLogger{
+				noopLogger: &noopLogger{name: "blocking"},
+				started:    make(chan struct{}, 10),
+				unblock:    make(chan struct{}),
+				written:    make(chan string, 10),
+			}
+			assert.Nil(t, m.New("blocking", func(string, ...interface{}) (Logger, error) { return l, nil }, 1, tt.overflow))
+
+			m.Info("1")
+			<-l.started // The first message is being written, the second one fills the buffer.
+			m.Info("2")
+			m.Info("3")
+
+			close(l.unblock)
+			m.Stop()
+			close(l.written)
+
+			var got []string
+			for s := range l.written {
+				got = append(got, s)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestManager_StopContext(t *testing.T) {
+	m := NewManager()
+
+	l := &blockingLogger{
+		noopLogger: &noopLogger{name: "blocking"},
+		started:    make(chan struct{}, 10),
+		unblock:    make(chan struct{}),
+		written:    make(chan string, 10),
+	}
+	assert.Nil(t, m.New("noop", noopIniter("noop")))
+	assert.Nil(t, m.New("blocking", func(string, ...interface{}) (Logger, error) { return l, nil }, 10))
+
+	m.Info("1")
+	m.Info("2")
+	<-l.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := m.StopContext(ctx)
+	assert.Equal(t, &DrainError{Loggers: []string{"blocking"}, Err: context.DeadlineExceeded}, err)
+	assert.Equal(t, "loggers not drained: blocking: context deadline exceeded", err.Error())
+
+	// The logger keeps draining in the background.
+	close(l.unblock)
+	assert.Equal(t, "[ INFO] 1", <-l.written)
+	assert.Equal(t, "[ INFO] 2", <-l.written)
+
+	// Stopping again is a noop.
+	assert.Nil(t, m.StopContext(context.Background()))
+}
+
+func TestManager_Flush(t *testing.T) {
+	m := NewManager()
+	defer m.Stop()
+
+	l := &blockingLogger{
+		noopLogger: &noopLogger{name: "blocking"},
+		started:    make(chan struct{}, 10),
+		unblock:    make(chan struct{}),
+		written:    make(chan string, 10),
+	}
+	assert.Nil(t, m.New("noop", noopIniter("noop"), 10))
+	assert.Nil(t, m.New("blocking", func(string, ...interface{}) (Logger, error) { return l, nil }, 10))
+
+	m.Info("1")
+	m.Info("2")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := m.Flush(ctx)
+	assert.Equal(t, &DrainError{Loggers: []string{"blocking"}, Err: context.DeadlineExceeded}, err)
+
+	close(l.unblock)
+	assert.Nil(t, m.Flush(context.Background()))
+	assert.Len(t, l.written, 2)
+
+	// Messages are still accepted after flushing.
+	m.Info("3")
+	assert.Nil(t, m.Flush(context.Background()))
+	assert.Len(t, l.written, 3)
+}
+
+func TestManager_Reset(t *testing.T) {
+	m := NewManager()
+	defer m.Stop()
+
+	m.SetCallerLevel(LevelTrace)
+	assert.Nil(t, m.New("noop", noopIniter("noop")))
+	m.Stop()
+
+	err := m.New("noop", noopIniter("noop"))
+	assert.Equal(t, ErrStopped, err)
+	assert.Equal(t, ErrStopped, m.ApplyConfig(&Config{}))
+
+	m.Reset()
+	assert.Equal(t, 0, m.len())
+	assert.Equal(t, LevelTrace, Level(atomic.LoadInt64(&m.callerLevel)))
+
+	c := make(chan string, 1)
+	assert.Nil(t, m.New("chan", chanLoggerIniter("chan", LevelTrace), chanConfig{c}))
+	m.Info("after reset")
+	assert.Regexp(t, `\] after reset$`, <-c)
+
+	// Resetting a running manager stops its loggers first.
+	m.Reset()
+	assert.Equal(t, 0, m.len())
+	assert.Nil(t, m.New("chan", chanLoggerIniter("chan", LevelTrace), chanConfig{c}))
+}
+
+var (
+	_ Starter   = (*lifecycleLogger)(nil)
+	_ Flusher   = (*lifecycleLogger)(nil)
+	_ io.Closer = (*lifecycleLogger)(nil)
+)
+
+// lifecycleLogger records calls of its methods to the events channel.
+type lifecycleLogger struct {
+	*noopLogger
+	id       string
+	events   chan string
+	startErr error
+	ctx      context.Context
+}
+
+func (l *lifecycleLogger) Start(ctx context.Context) error {
+	l.ctx = ctx
+	l.events <- l.id + ": start"
+	return l.startErr
+}
+
+func (l *lifecycleLogger) Write(m Messager) error {
+	l.events <- l.id + ": write " + m.Text()
+	return nil
+}
+
+func (l *lifecycleLogger) Flush() error {
+	l.events <- l.id + ": flush"
+	return nil
+}
+
+func (l *lifecycleLogger) Close() error {
+	l.events <- l.id + ": close"
+	return nil
+}
+
+func TestManager_lifecycle(t *testing.T) {
+	m := NewManager()
+	defer m.Stop()
+
+	events := make(chan string, 100)
+	newLogger := func(id string, startErr error) *lifecycleLogger {
+		return &lifecycleLogger{
+			noopLogger: &noopLogger{name: "lifecycle"},
+			id:         id,
+			events:     events,
+			startErr:   startErr,
+		}
+	}
+	nextEvents := func(n int) []string {
+		got := make([]string, n)
+		for i := range got {
+			got[i] = <-events
+		}
+		return got
+	}
+
+	l1 := newLogger("l1", nil)
+	assert.Nil(t, m.New("lifecycle", func(string, ...interface{}) (Logger, error) { return l1, nil }, 10))
+	assert.Equal(t, []string{"l1: start"}, nextEvents(1))
+
+	m.Info("1")
+	assert.Nil(t, m.Flush(context.Background()))
+	assert.Equal(t, []string{"l1: write 1", "l1: flush"}, nextEvents(2))
+
+	// The replaced logger is flushed and closed after its queued messages are
+	// written.
+	l2 := newLogger("l2", nil)
+	m.Info("2")
+	assert.Nil(t, m.New("lifecycle", func(string, ...interface{}) (Logger, error) { return l2, nil }, 10))
+	assert.Equal(t, []string{"l2: start", "l1: write 2", "l1: flush", "l1: close"}, nextEvents(4))
+	assert.NotNil(t, l1.ctx.Err())
+	assert.Nil(t, l2.ctx.Err())
+
+	// A logger that fails to start is closed and not added.
+	l3 := newLogger("l3", errors.New("boom"))
+	err := m.New("lifecycle", func(string, ...interface{}) (Logger, error) { return l3, nil })
+	assert.Equal(t, errors.New("start logger: boom"), err)
+	assert.Equal(t, []string{"l3: start", "l3: flush", "l3: close"}, nextEvents(3))
+
+	m.Info("3")
+	m.Stop()
+	assert.Equal(t, []string{"l2: write 3", "l2: flush", "l2: close"}, nextEvents(3))
+	assert.NotNil(t, l2.ctx.Err())
+}
+
+var _ Logger = (*failingLogger)(nil)
+
+// failingLogger fails every write.
+type failingLogger struct {
+	*noopLogger
+}
+
+func (l *failingLogger) Write(Messager) error {
+	return errors.New("disk is full")
+}
+
+func TestManager_SetErrorHandler(t *testing.T) {
+	m := NewManager()
+	defer m.Stop()
+
+	type handled struct {
+		name string
+		text string
+		err  string
+	}
+	c := make(chan handled, 1)
+	m.SetErrorHandler(func(name string, msg Messager, err error) {
+		h := handled{name: name, err: err.Error()}
+		if msg != nil {
+			h.text = msg.Text()
+		}
+		c <- h
+	})
+
+	m.Info("nobody listens")
+	assert.Equal(t, handled{text: "nobody listens", err: "no logger is available"}, <-c)
+
+	assert.Nil(t, m.New("failing", func(string, ...interface{}) (Logger, error) {
+		return &failingLogger{noopLogger: &noopLogger{name: "failing"}}, nil
+	}))
+	m.Info("to the disk")
+	assert.Equal(t, handled{name: "failing", text: "to the disk", err: "disk is full"}, <-c)
+	info, _ := m.LoggerInfo("failing")
+	assert.Equal(t, uint64(1), info.WriteErrors)
+
+	m.WarnTo("bob", "hello %s", "bob")
+	assert.Equal(t, handled{name: "bob", text: "hello bob", err: "logger is not available"}, <-c)
+
+	// Restore the default handler.
+	m.SetErrorHandler(nil)
+	m.Info("printed")
+	select {
+	case h := <-c:
+		t.Fatalf("unexpected call of the handler: %+v", h)
+	default:
+	}
+}
+
+func TestManager_SetLevel(t *testing.T) {
+	m := NewManager()
+	defer m.Stop()
+
+	c := make(chan string, 1)
+	assert.Nil(t, m.New("alice", chanLoggerIniter("alice", LevelInfo), chanConfig{c: c}))
+
+	m.Trace("trace")
+	m.Info("info")
+	assert.Equal(t, "[ INFO] info", <-c)
+
+	assert.Nil(t, m.SetLevel("alice", LevelTrace))
+	assert.Equal(t, LevelTrace, m.loggers()[0].Level())
+	m.Trace("trace")
+	assert.Equal(t, "[TRACE] trace", <-c)
+
+	assert.Equal(t, errors.New(`logger with name "bob" is not available`), m.SetLevel("bob", LevelTrace))
+}
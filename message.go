@@ -4,53 +4,242 @@ import (
 	"fmt"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// Fields is a set of key-value pairs attached to a message. Any value of this
+// type passed along with the format arguments will be attached to the message
+// instead of being formatted, e.g.
+//
+//	clog.Info("user login", clog.Fields{"user_id": 42, "ip": addr})
+type Fields map[string]interface{}
+
+// Keys returns keys of the fields in sorted order.
+func (fs Fields) Keys() []string {
+	keys := make([]string, 0, len(fs))
+	for k := range fs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// String returns the fields in the form of "key1=value1 key2=value2" with keys
+// in sorted order. Values that contain spaces or quotes are quoted, and spaces,
+// quotes, equal signs and control characters in keys are replaced with "_".
+func (fs Fields) String() string {
+	var buf strings.Builder
+	for i, k := range fs.Keys() {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(sanitizeFieldKey(k))
+		buf.WriteByte('=')
+		buf.WriteString(quoteFieldValue(fmt.Sprint(fs[k])))
+	}
+	return buf.String()
+}
+
+func quoteFieldValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// sanitizeFieldKey replaces characters that are not allowed in a logfmt key
+// with "_", an empty key becomes "_".
+func sanitizeFieldKey(k string) string {
+	if k == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f {
+			return '_'
+		}
+		return r
+	}, k)
+}
+
+// withFields returns s with rendered fields appended, or s itself if there are
+// no fields.
+func withFields(s string, fs Fields) string {
+	if len(fs) == 0 {
+		return s
+	}
+	return s + " " + fs.String()
+}
+
+// extractFields separates values of the Fields type from format arguments and
+// merges them in the given order.
+func extractFields(v []interface{}) ([]interface{}, Fields) {
+	var fields Fields
+	args := make([]interface{}, 0, len(v))
+	for i := range v {
+		fs, ok := v[i].(Fields)
+		if !ok {
+			args = append(args, v[i])
+			continue
+		}
+
+		if fields == nil {
+			fields = make(Fields, len(fs))
+		}
+		for k, val := range fs {
+			fields[k] = val
+		}
+	}
+	return args, fields
+}
+
+// CallerPath specifies how the file path of a caller is rendered.
+type CallerPath int
+
+const (
+	// CallerPathShort renders at most the last 32 characters of the file path.
+	CallerPathShort CallerPath = iota
+	// CallerPathFull renders the full file path.
+	CallerPathFull
+	// CallerPathPackage renders the import path of the package followed by the
+	// file name, e.g. "unknwon.dev/clog/v2/clog.go".
+	CallerPathPackage
+)
+
+// Caller is the source code location where a message is created.
+type Caller struct {
+	// Full path of the source file.
+	File string
+	// Line number in the source file.
+	Line int
+	// Fully-qualified function name, e.g. "main.main".
+	Function string
+}
+
+// Path returns the file path of the caller in given format.
+func (c *Caller) Path(p CallerPath) string {
+	switch p {
+	case CallerPathFull:
+		return c.File
+	case CallerPathPackage:
+		pkg := c.Function
+		if i := strings.LastIndex(pkg, "/"); i >= 0 {
+			if j := strings.Index(pkg[i:], "."); j >= 0 {
+				pkg = pkg[:i+j]
+			}
+		} else if j := strings.Index(pkg, "."); j >= 0 {
+			pkg = pkg[:j]
+		}
+		return pkg + "/" + filepath.Base(c.File)
+	default:
+		if len(c.File) > 32 {
+			return "..." + c.File[len(c.File)-32:]
+		}
+		return c.File
+	}
+}
+
+// FuncName returns the short name of the function, e.g. "main()".
+func (c *Caller) FuncName() string {
+	if c.Function == "" {
+		return "?()"
+	}
+	return strings.TrimLeft(filepath.Ext(c.Function), ".") + "()"
+}
+
+// format returns the caller in the form of "path:line func()".
+func (c *Caller) format(p CallerPath) string {
+	return fmt.Sprintf("%s:%d %s", c.Path(p), c.Line, c.FuncName())
+}
+
 var _ Messager = (*message)(nil)
 
 // Messager is a message entry to be processed by logger.
 type Messager interface {
 	// Level returns the level of the message.
 	Level() Level
+	// Text returns the formatted text of the message without level prefix and
+	// caller information.
+	Text() string
+	// Fields returns the key-value pairs attached to the message. The returned
+	// value must not be modified.
+	Fields() Fields
+	// Caller returns the source code location where the message is created, or
+	// nil if it is not captured.
+	Caller() *Caller
+	// Time returns the time when the message is created.
+	Time() time.Time
 	fmt.Stringer
 }
 
 type message struct {
-	level Level
-	body  string
+	level  Level
+	text   string
+	body   string
+	fields Fields
+	caller *Caller
+	time   time.Time
 }
 
+// newMessage creates a new message with formatted text. It captures the
+// caller with given skip depth, but if skip is 0 means caller doesn't care so
+// we can skip.
 func newMessage(level Level, skip int, format string, v ...interface{}) *message {
-	var body string
-	// Only error and fatal information needs locate position for debugging.
-	// But if skip is 0 means caller doesn't care so we can skip.
-	if level >= LevelError && skip > 0 {
+	now := time.Now()
+	v, fields := extractFields(v)
+
+	var caller *Caller
+	if skip > 0 {
 		pc, file, line, ok := runtime.Caller(skip)
 		if ok {
-			// Get caller function name
-			fn := runtime.FuncForPC(pc)
-			var fnName string
-			if fn == nil {
-				fnName = "?()"
-			} else {
-				fnName = strings.TrimLeft(filepath.Ext(fn.Name()), ".") + "()"
+			caller = &Caller{
+				File: file,
+				Line: line,
 			}
 
-			if len(file) > 32 {
-				file = "..." + file[len(file)-32:]
+			// Get caller function name
+			if fn := runtime.FuncForPC(pc); fn != nil {
+				caller.Function = fn.Name()
 			}
-			body = fmt.Sprintf("[%s:%d %s] %s", file, line, fnName, fmt.Sprintf(format, v...))
 		}
 	}
-	if len(body) == 0 {
-		body = fmt.Sprintf(format, v...)
+
+	m := &message{
+		level:  level,
+		text:   fmt.Sprintf(format, v...),
+		fields: fields,
+		caller: caller,
+		time:   now,
+	}
+	m.body = m.render()
+	return m
+}
+
+// render returns the message in the form of "[LEVEL] [caller] text".
+func (m *message) render() string {
+	if m.caller == nil {
+		return fmt.Sprintf("[%5s] %s", m.level, m.text)
 	}
-	return &message{
-		level: level,
-		body:  fmt.Sprintf("[%5s] %s", level, body),
+	return fmt.Sprintf("[%5s] [%s] %s", m.level, m.caller.format(CallerPathShort), m.text)
+}
+
+// withoutCaller returns a copy of the message without caller information.
+func (m *message) withoutCaller() *message {
+	if m.caller == nil {
+		return m
 	}
+
+	c := *m
+	c.caller = nil
+	c.body = c.render()
+	return &c
 }
 
-func (m *message) Level() Level   { return m.level }
-func (m *message) String() string { return m.body }
+func (m *message) Level() Level    { return m.level }
+func (m *message) Text() string    { return m.text }
+func (m *message) Fields() Fields  { return m.fields }
+func (m *message) Caller() *Caller { return m.caller }
+func (m *message) Time() time.Time { return m.time }
+func (m *message) String() string  { return m.body }
@@ -1,6 +1,9 @@
 package clog
 
 import (
+	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -62,54 +65,161 @@ func Test_newMessage(t *testing.T) {
 
 	t.Run("has skip", func(t *testing.T) {
 		tests := []struct {
-			name     string
-			level    Level
-			format   string
-			v        []interface{}
-			contains string
+			name   string
+			level  Level
+			format string
+			v      []interface{}
+			prefix string
+			text   string
 		}{
 			{
-				name:     "trace",
-				level:    LevelTrace,
-				format:   "a trace log: %v",
-				v:        []interface{}{"value"},
-				contains: "[TRACE] a trace log: value",
+				name:   "trace",
+				level:  LevelTrace,
+				format: "a trace log: %v",
+				v:      []interface{}{"value"},
+				prefix: "[TRACE] ",
+				text:   "a trace log: value",
 			},
 			{
-				name:     "info",
-				level:    LevelInfo,
-				format:   "a info log: %v",
-				v:        []interface{}{"value"},
-				contains: "[ INFO] a info log: value",
+				name:   "info",
+				level:  LevelInfo,
+				format: "a info log: %v",
+				v:      []interface{}{"value"},
+				prefix: "[ INFO] ",
+				text:   "a info log: value",
 			},
 			{
-				name:     "warn",
-				level:    LevelWarn,
-				format:   "a warn log: %v",
-				v:        []interface{}{"value"},
-				contains: "[ WARN] a warn log: value",
+				name:   "warn",
+				level:  LevelWarn,
+				format: "a warn log: %v",
+				v:      []interface{}{"value"},
+				prefix: "[ WARN] ",
+				text:   "a warn log: value",
 			},
 			{
-				name:     "error",
-				level:    LevelError,
-				format:   "an error log: %v",
-				v:        []interface{}{"value"},
-				contains: "an error log: value",
+				name:   "error",
+				level:  LevelError,
+				format: "an error log: %v",
+				v:      []interface{}{"value"},
+				prefix: "[ERROR] ",
+				text:   "an error log: value",
 			},
 			{
-				name:     "fatal",
-				level:    LevelFatal,
-				format:   "a fatal log: %v",
-				v:        []interface{}{"value"},
-				contains: "()] a fatal log: value",
+				name:   "fatal",
+				level:  LevelFatal,
+				format: "a fatal log: %v",
+				v:      []interface{}{"value"},
+				prefix: "[FATAL] ",
+				text:   "a fatal log: value",
 			},
 		}
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
 				m := newMessage(tt.level, 1, tt.format, tt.v...)
 				assert.Equal(t, tt.level, m.Level())
-				assert.Contains(t, m.String(), tt.contains)
+				assert.Equal(t, tt.text, m.Text())
+				assert.True(t, strings.HasPrefix(m.String(), tt.prefix), m.String())
+				assert.True(t, strings.HasSuffix(m.String(), "()] "+tt.text), m.String())
+
+				// The caller is the test function itself.
+				c := m.Caller()
+				if assert.NotNil(t, c) {
+					assert.Equal(t, "message_test.go", filepath.Base(c.File))
+					assert.Contains(t, m.String(), fmt.Sprintf("[%s:%d %s] ", c.File, c.Line, c.FuncName()))
+				}
 			})
 		}
 	})
 }
+
+func Test_newMessage_fields(t *testing.T) {
+	m := newMessage(LevelInfo, 0, "user %s login", "alice", Fields{"user_id": 42}, Fields{"ip": "127.0.0.1"})
+	assert.Equal(t, "[ INFO] user alice login", m.String())
+	assert.Equal(t, Fields{"user_id": 42, "ip": "127.0.0.1"}, m.Fields())
+
+	m = newMessage(LevelInfo, 0, "no fields")
+	assert.Nil(t, m.Fields())
+}
+
+func TestFields_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields Fields
+		want   string
+	}{
+		{
+			name:   "empty",
+			fields: nil,
+			want:   "",
+		},
+		{
+			name:   "sorted keys",
+			fields: Fields{"b": 2, "a": 1},
+			want:   "a=1 b=2",
+		},
+		{
+			name:   "quoted values",
+			fields: Fields{"msg": "hello world", "empty": "", "quote": `"`},
+			want:   `empty="" msg="hello world" quote="\""`,
+		},
+		{
+			name:   "sanitized keys",
+			fields: Fields{"bad key": 1, "a=b": 2, `q"`: 3, "tab\t": 4, "": 5},
+			want:   `_=5 a_b=2 bad_key=1 q_=3 tab_=4`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.fields.String())
+		})
+	}
+}
+
+func TestCaller_Path(t *testing.T) {
+	c := &Caller{
+		File:     "/home/unknwon/go/src/github.com/go-clog/clog/internal/example/main.go",
+		Line:     64,
+		Function: "github.com/go-clog/clog/internal/example.(*Server).Run",
+	}
+
+	tests := []struct {
+		name string
+		path CallerPath
+		want string
+	}{
+		{
+			name: "short",
+			path: CallerPathShort,
+			want: "...og/clog/internal/example/main.go",
+		},
+		{
+			name: "full",
+			path: CallerPathFull,
+			want: c.File,
+		},
+		{
+			name: "package",
+			path: CallerPathPackage,
+			want: "github.com/go-clog/clog/internal/example/main.go",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, c.Path(tt.path))
+		})
+	}
+
+	assert.Equal(t, "Run()", c.FuncName())
+	assert.Equal(t, "main/main.go", (&Caller{File: "/tmp/main.go", Function: "main.main"}).Path(CallerPathPackage))
+}
+
+func Test_message_withoutCaller(t *testing.T) {
+	m := newMessage(LevelError, 1, "an error log")
+	assert.NotNil(t, m.Caller())
+	assert.Contains(t, m.String(), "message_test.go")
+
+	c := m.withoutCaller()
+	assert.Nil(t, c.Caller())
+	assert.Equal(t, "[ERROR] an error log", c.String())
+	assert.Equal(t, "an error log", c.Text())
+}
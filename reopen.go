@@ -0,0 +1,78 @@
+package clog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// Reopen reopens files of all loggers that implement Reopener, e.g. after the
+// files are moved by external rotation tools such as logrotate. It returns an
+// error of all loggers that failed to reopen.
+func (m *Manager) Reopen() error {
+	var errs []string
+	for _, l := range m.loggers() {
+		r, ok := l.Logger.(Reopener)
+		if !ok {
+			continue
+		}
+
+		if err := r.Reopen(); err != nil {
+			errs = append(errs, fmt.Sprintf("logger %q: %v", l.Name(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// ReopenOnSignal calls Reopen whenever any of given signals is received, it
+// listens to SIGHUP if no signal is given. It stops listening once the context
+// is done or the manager is stopped. Errors of reopening are reported to the
+// error handler.
+func (m *Manager) ReopenOnSignal(ctx context.Context, sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sigs...)
+
+	m.mu.Lock()
+	stopped := m.ctx.Done()
+	m.mu.Unlock()
+	go func() {
+		defer signal.Stop(c)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stopped:
+				return
+			case <-c:
+			}
+
+			if err := m.Reopen(); err != nil {
+				m.handleError("", nil, fmt.Errorf("reopen: %v", err))
+			}
+		}
+	}()
+}
+
+// Reopen reopens files of all loggers that implement Reopener in the default
+// manager.
+func Reopen() error {
+	return mgr.Reopen()
+}
+
+// ReopenOnSignal calls Reopen of the default manager whenever any of given
+// signals is received, it listens to SIGHUP if no signal is given.
+func ReopenOnSignal(ctx context.Context, sigs ...os.Signal) {
+	mgr.ReopenOnSignal(ctx, sigs...)
+}
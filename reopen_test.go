@@ -0,0 +1,102 @@
+package clog
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_Reopen(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping testing on Windows")
+	}
+
+	_ = os.MkdirAll("test", os.ModePerm)
+	defer os.RemoveAll("test")
+
+	m := NewManager()
+	defer m.Stop()
+
+	filename := filepath.Join("test", "app.log")
+	assert.Nil(t, m.New("file", FileIniter(), FileConfig{
+		Filename: filename,
+		FileRotationConfig: FileRotationConfig{
+			Rotate:   true,
+			MaxSize:  1 << 20,
+			MaxLines: 1000,
+		},
+	}))
+	assert.Nil(t, m.New("noop", noopIniter("noop")))
+
+	m.Info("before reopen")
+	assert.Nil(t, m.Flush(context.Background()))
+
+	// Move the file away like logrotate does.
+	moved := filepath.Join("test", "app.log.1")
+	assert.Nil(t, os.Rename(filename, moved))
+	assert.Nil(t, m.Reopen())
+
+	l, _ := m.lookup("file")
+	fl := l.Logger.(*fileLogger)
+	assert.Equal(t, int64(0), fl.currentSize)
+	assert.Equal(t, int64(0), fl.currentLines)
+
+	m.Info("after reopen")
+	assert.Nil(t, m.Flush(context.Background()))
+
+	data, err := ioutil.ReadFile(moved)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "before reopen")
+	assert.NotContains(t, string(data), "after reopen")
+
+	data, err = ioutil.ReadFile(filename)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "after reopen")
+	assert.NotContains(t, string(data), "before reopen")
+
+	// Counters are taken from the reopened file if it has content.
+	assert.Nil(t, m.Reopen())
+	assert.Equal(t, int64(len(data)), fl.currentSize)
+	assert.Equal(t, int64(strings.Count(string(data), "\n")), fl.currentLines)
+
+	// A closed file cannot be reopened.
+	assert.Nil(t, fl.Close())
+	assert.Equal(t, fmt.Errorf(`logger "file": file %q is closed`, filename), m.Reopen())
+}
+
+func TestManager_ReopenOnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping testing on Windows")
+	}
+
+	_ = os.MkdirAll("test", os.ModePerm)
+	defer os.RemoveAll("test")
+
+	m := NewManager()
+	defer m.Stop()
+
+	filename := filepath.Join("test", "app.log")
+	assert.Nil(t, m.New("file", FileIniter(), FileConfig{Filename: filename}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.ReopenOnSignal(ctx)
+
+	assert.Nil(t, os.Rename(filename, filepath.Join("test", "app.log.1")))
+
+	p, err := os.FindProcess(os.Getpid())
+	assert.Nil(t, err)
+	assert.Nil(t, p.Signal(syscall.SIGHUP))
+	assert.Eventually(t, func() bool {
+		return isExist(filename)
+	}, time.Second, 10*time.Millisecond)
+}
@@ -8,11 +8,25 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 )
 
+// webhookClient is the default HTTP client used by the Slack and Discord
+// loggers, the timeout prevents a hung request from blocking the logger
+// forever.
+var webhookClient = &http.Client{Timeout: 30 * time.Second}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
 type slackAttachment struct {
-	Text  string `json:"text"`
-	Color string `json:"color"`
+	Text      string       `json:"text"`
+	Color     string       `json:"color"`
+	Fields    []slackField `json:"fields,omitempty"`
+	Timestamp int64        `json:"ts,omitempty"`
 }
 
 type slackPayload struct {
@@ -36,6 +50,37 @@ type SlackConfig struct {
 	// Colors for different levels, must have exact 5 elements in the order of
 	// Trace, Info, Warn, Error, and Fatal.
 	Colors []string
+	// HTTP client to send webhook requests. Leave nil to use the default client
+	// with a 30-second timeout.
+	Client *http.Client
+}
+
+// SlackOption is a typed option of the Slack logger, it is applied on top of
+// the SlackConfig if any.
+type SlackOption func(*SlackConfig)
+
+// WithSlackURL sets the webhook URL of the Slack logger.
+func WithSlackURL(url string) SlackOption {
+	return func(cfg *SlackConfig) {
+		cfg.URL = url
+	}
+}
+
+// WithSlackColors sets the colors for different levels of the Slack logger,
+// it must have exact 5 elements in the order of Trace, Info, Warn, Error, and
+// Fatal.
+func WithSlackColors(colors []string) SlackOption {
+	return func(cfg *SlackConfig) {
+		cfg.Colors = colors
+	}
+}
+
+// WithSlackClient sets the HTTP client to send webhook requests of the Slack
+// logger.
+func WithSlackClient(client *http.Client) SlackOption {
+	return func(cfg *SlackConfig) {
+		cfg.Client = client
+	}
 }
 
 var _ Logger = (*slackLogger)(nil)
@@ -50,11 +95,28 @@ type slackLogger struct {
 }
 
 func (l *slackLogger) buildPayload(m Messager) (string, error) {
+	fields := m.Fields()
+	var attachmentFields []slackField
+	for _, k := range fields.Keys() {
+		attachmentFields = append(attachmentFields, slackField{
+			Title: k,
+			Value: fmt.Sprint(fields[k]),
+			Short: true,
+		})
+	}
+
+	var ts int64
+	if !m.Time().IsZero() {
+		ts = m.Time().Unix()
+	}
+
 	payload := slackPayload{
 		Attachments: []slackAttachment{
 			{
-				Text:  m.String(),
-				Color: l.colors[m.Level()],
+				Text:      m.String(),
+				Color:     l.colors[m.Level()],
+				Fields:    attachmentFields,
+				Timestamp: ts,
 			},
 		},
 	}
@@ -95,6 +157,12 @@ func (l *slackLogger) Write(m Messager) error {
 	return nil
 }
 
+// ModeSlack is the mode name of the Slack logger.
+const ModeSlack = "slack"
+
+// Mode returns the mode name of the logger.
+func (*slackLogger) Mode() string { return ModeSlack }
+
 // DefaultSlackName is the default name for the Slack logger.
 const DefaultSlackName = "slack"
 
@@ -110,20 +178,54 @@ func NewSlackWithName(name string, vs ...interface{}) error {
 	return New(name, SlackIniter(), vs...)
 }
 
+// SlackOptioner is a typed option accepted by NewSlackWithOptions, it is
+// implemented by Option and SlackOption only.
+type SlackOptioner interface {
+	slackOption()
+}
+
+func (Option) slackOption()      {}
+func (SlackOption) slackOption() {}
+
+// NewSlackWithOptions initializes and appends a new Slack logger with given
+// name to the managed list. Unlike NewSlackWithName, only typed options are
+// accepted so that mistakes are caught at compile time.
+func NewSlackWithOptions(name string, opts ...SlackOptioner) error {
+	vs := make([]interface{}, len(opts))
+	for i := range opts {
+		vs[i] = opts[i]
+	}
+	return New(name, SlackIniter(), vs...)
+}
+
 // SlackIniter returns the initer for the Slack logger.
 func SlackIniter() Initer {
 	return func(name string, vs ...interface{}) (Logger, error) {
 		var cfg *SlackConfig
+		var opts []SlackOption
 		for i := range vs {
 			switch v := vs[i].(type) {
 			case SlackConfig:
 				cfg = &v
+			case SlackOption:
+				opts = append(opts, v)
+			case nil:
+			default:
+				return nil, fmt.Errorf("unrecognized option of type '%T'", v)
 			}
 		}
 
 		if cfg == nil {
-			return nil, fmt.Errorf("config object with the type '%T' not found", SlackConfig{})
-		} else if cfg.URL == "" {
+			if len(opts) == 0 {
+				return nil, fmt.Errorf("config object with the type '%T' not found", SlackConfig{})
+			}
+			cfg = &SlackConfig{}
+		}
+		for _, opt := range opts {
+			opt(cfg)
+		}
+
+		if cfg.URL == "" {
 			return nil, errors.New("empty URL")
 		}
 
@@ -135,6 +237,11 @@ func SlackIniter() Initer {
 			colors = cfg.Colors
 		}
 
+		client := webhookClient
+		if cfg.Client != nil {
+			client = cfg.Client
+		}
+
 		return &slackLogger{
 			noopLogger: &noopLogger{
 				name:  name,
@@ -142,7 +249,7 @@ func SlackIniter() Initer {
 			},
 			url:    cfg.URL,
 			colors: colors,
-			client: http.DefaultClient,
+			client: client,
 		}, nil
 	}
 }
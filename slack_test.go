@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -49,7 +50,23 @@ func Test_slackLogger(t *testing.T) {
 		{
 			name:    "invalid config",
 			config:  "random things",
-			wantErr: errors.New("initialize logger: config object with the type 'clog.SlackConfig' not found"),
+			wantErr: errors.New("initialize logger: unrecognized option of type 'string'"),
+		},
+		{
+			name:    "config of other mode",
+			config:  FileConfig{},
+			wantErr: errors.New("initialize logger: unrecognized option of type 'clog.FileConfig'"),
+		},
+		{
+			name:    "typed options",
+			mode:    testName,
+			config:  WithSlackURL("https://slack.com"),
+			wantErr: nil,
+		},
+		{
+			name:    "typed options without URL",
+			config:  WithSlackColors(slackColors),
+			wantErr: errors.New("initialize logger: empty URL"),
 		},
 		{
 			name:    "invalid URL",
@@ -72,10 +89,10 @@ func Test_slackLogger(t *testing.T) {
 	}
 
 	assert.Equal(t, 2, mgr.len())
-	assert.Equal(t, DefaultSlackName, mgr.loggers[0].Name())
-	assert.Equal(t, LevelInfo, mgr.loggers[0].Level())
-	assert.Equal(t, testName, mgr.loggers[1].Name())
-	assert.Equal(t, LevelTrace, mgr.loggers[1].Level())
+	assert.Equal(t, DefaultSlackName, mgr.loggers()[0].Name())
+	assert.Equal(t, LevelInfo, mgr.loggers()[0].Level())
+	assert.Equal(t, testName, mgr.loggers()[1].Name())
+	assert.Equal(t, LevelTrace, mgr.loggers()[1].Level())
 }
 
 func Test_slackLogger_buildPayload(t *testing.T) {
@@ -200,6 +217,21 @@ func Test_slackLogger_buildPayload(t *testing.T) {
 	})
 }
 
+func Test_slackLogger_buildPayload_fields(t *testing.T) {
+	l := &slackLogger{
+		colors: slackColors,
+	}
+
+	payload, err := l.buildPayload(&message{
+		level:  LevelInfo,
+		body:   "test message",
+		fields: Fields{"user_id": 42, "ip": "127.0.0.1"},
+		time:   time.Unix(1606033815, 0),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"attachments":[{"text":"test message","color":"#3aa3e3","fields":[{"title":"ip","value":"127.0.0.1","short":true},{"title":"user_id","value":"42","short":true}],"ts":1606033815}]}`, payload)
+}
+
 type roundTripFunc func(req *http.Request) *http.Response
 
 func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -253,3 +285,39 @@ func Test_slackLogger_postMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestNewSlackWithOptions(t *testing.T) {
+	testName := "TestNewSlackWithOptions"
+	defer Remove(testName)
+
+	assert.Equal(t,
+		errors.New("initialize logger: empty URL"),
+		NewSlackWithOptions(testName, WithSlackColors(slackColors)),
+	)
+
+	err := NewSlackWithOptions(testName,
+		WithBufferSize(10),
+		WithLevel(LevelError),
+		WithSlackURL("https://slack.com"),
+	)
+	assert.Nil(t, err)
+
+	l, ok := mgr.lookup(testName)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, 10, cap(l.msgChan))
+	assert.Equal(t, LevelError, l.Level())
+	assert.Equal(t, "https://slack.com", l.Logger.(*slackLogger).url)
+}
+
+func TestSlackIniter_client(t *testing.T) {
+	l, err := SlackIniter()("TestSlackIniter_client", WithSlackURL("https://slack.com"))
+	assert.Nil(t, err)
+	assert.Same(t, webhookClient, l.(*slackLogger).client)
+
+	client := &http.Client{Timeout: time.Second}
+	l, err = SlackIniter()("TestSlackIniter_client", SlackConfig{URL: "https://slack.com", Client: client})
+	assert.Nil(t, err)
+	assert.Same(t, client, l.(*slackLogger).client)
+}